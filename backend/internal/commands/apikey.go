@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// APIKey implements `cyber-go apikey create|revoke|list` for the tenant of
+// ctx.
+func APIKey(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: apikey create -name name -scopes scope,... [-expires duration] [-quota n] | apikey revoke id | apikey list")
+	}
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "who the key is for")
+		scopes := fs.String("scopes", "", "comma-separated scopes: read:questions, submit, read:results")
+		expires := fs.Duration("expires", 0, "lifetime of the key, such as 2160h (default: never expires)")
+		quota := fs.Int("quota", 0, "requests allowed per UTC day (default: unlimited)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 0 {
+			return errors.New("usage: apikey create -name name -scopes scope,... [-expires duration] [-quota n]")
+		}
+		k := models.APIKey{Name: *name, DailyQuota: *quota}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				k.Scopes = append(k.Scopes, scope)
+			}
+		}
+		if *expires != 0 {
+			at := time.Now().UTC().Add(*expires)
+			k.ExpiresAt = &at
+		}
+		k, key, err := h.CreateAPIKey(ctx, k)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created API key %s for tenant %s\n", k.ID, k.Tenant)
+		fmt.Fprintf(out, "%s\n", key)
+		fmt.Fprintln(out, "store the key now, it cannot be shown again")
+		return nil
+	case "revoke":
+		if len(args) != 2 {
+			return errors.New("usage: apikey revoke id")
+		}
+		err := h.RevokeAPIKey(ctx, args[1])
+		if errors.Is(err, repositories.ErrNotFound) {
+			return fmt.Errorf("API key %q not found", args[1])
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "revoked API key %s\n", args[1])
+		return nil
+	case "list":
+		keys, err := h.APIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, k := range keys {
+			status, expires := "active", "never"
+			switch {
+			case k.RevokedAt != nil:
+				status = "revoked"
+			case !k.Active(now):
+				status = "expired"
+			}
+			if k.ExpiresAt != nil {
+				expires = k.ExpiresAt.Format(time.RFC3339)
+			}
+			quota := "unlimited"
+			if k.DailyQuota > 0 {
+				quota = fmt.Sprintf("%d/day", k.DailyQuota)
+			}
+			fmt.Fprintf(out, "%s\t%s\t%s\t%s\t%s\texpires %s\n", k.ID, k.Name, strings.Join(k.Scopes, ","), quota, status, expires)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown apikey command %q", args[0])
+	}
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+)
+
+// Catalog implements `cyber-go catalog export|import`: it writes the active
+// catalog and the tier table of the tenant of ctx to a bundle file, or makes
+// the database match one.
+func Catalog(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: catalog export [-format yaml|json] [-o file] | catalog import [-dry-run] file")
+	}
+	switch args[0] {
+	case "export":
+		return catalogExport(ctx, h, args[1:], out)
+	case "import":
+		return catalogImport(ctx, h, args[1:], out)
+	default:
+		return fmt.Errorf("unknown catalog command %q", args[0])
+	}
+}
+
+func catalogExport(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("catalog export", flag.ContinueOnError)
+	format := fs.String("format", "yaml", "bundle format: yaml or json")
+	file := fs.String("o", "", "write the bundle to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	b, err := h.ExportBundle(ctx)
+	if err != nil {
+		return err
+	}
+	data, err := EncodeBundle(b, *format)
+	if err != nil {
+		return err
+	}
+	if *file != "" {
+		return os.WriteFile(*file, data, 0o644)
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+func catalogImport(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("catalog import", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "print the changes without writing them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: catalog import [-dry-run] file")
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	b, err := DecodeBundle(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", fs.Arg(0), err)
+	}
+
+	plan, err := h.ImportBundle(ctx, b, *dryRun)
+	if err != nil {
+		return err
+	}
+	WriteCatalogChanges(out, plan.Changes)
+	switch {
+	case len(plan.Changes) == 0:
+		fmt.Fprintln(out, "catalog is up to date")
+	case *dryRun:
+		fmt.Fprintf(out, "%d change(s), dry run: nothing written\n", len(plan.Changes))
+	default:
+		fmt.Fprintf(out, "%d change(s) imported\n", len(plan.Changes))
+		if plan.Tiers != nil {
+			fmt.Fprintf(out, "running servers score with tier table version %d within their CONFIG_CACHE_TTL (default %s)\n",
+				plan.Tiers.Version, handlers.DefaultConfigTTL)
+		}
+	}
+	return nil
+}
+
+// EncodeBundle writes b as indented JSON or as YAML with the same field
+// names.
+func EncodeBundle(b models.Bundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case "json":
+		return append(data, '\n'), nil
+	case "yaml":
+		// JSON is YAML: parse it into a node tree, which keeps the field
+		// order, and re-emit it in block style.
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		blockStyle(&doc)
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&doc); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), enc.Close()
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// DecodeBundle reads a YAML or JSON bundle. Unknown fields are rejected so
+// typos do not silently drop settings.
+func DecodeBundle(data []byte) (models.Bundle, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return models.Bundle{}, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+
+	var b models.Bundle
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&b); err != nil {
+		return models.Bundle{}, err
+	}
+	return b, nil
+}
+
+// WriteCatalogChanges prints an import plan as a diff: + creates, ~ updates
+// and - retires.
+func WriteCatalogChanges(w io.Writer, changes []models.CatalogChange) {
+	marks := map[string]string{controllers.ChangeCreate: "+", controllers.ChangeUpdate: "~", controllers.ChangeRetire: "-"}
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s %d\n", marks[c.Action], c.Kind, c.ID)
+		for _, f := range c.Fields {
+			switch {
+			case c.Action == controllers.ChangeCreate:
+				fmt.Fprintf(w, "    %s: %s\n", f.Field, f.New)
+			case f.New == "":
+				fmt.Fprintf(w, "    %s: %s -> (none)\n", f.Field, f.Old)
+			case f.Old == "":
+				fmt.Fprintf(w, "    %s: (none) -> %s\n", f.Field, f.New)
+			default:
+				fmt.Fprintf(w, "    %s: %s -> %s\n", f.Field, f.Old, f.New)
+			}
+		}
+	}
+}
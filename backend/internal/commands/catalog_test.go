@@ -0,0 +1,125 @@
+package commands_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"cyber-go/internal/commands"
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+const bundleYAML = `paradigms:
+  - id: 1
+    name: Threat
+  - id: 2
+    name: Vulnerability
+    description: Internal weaknesses
+questions:
+  - id: 1
+    paradigm: "1"
+    text: Do you use MFA?
+    selector: radio
+    weight: 10
+    required: true
+    options: ["Yes", "No"]
+  - id: 2
+    paradigm: "2"
+    text: How often do you test restores?
+    selector: dropdown
+    weight: 15
+    required: false
+    choices:
+      - {label: Quarterly, score: 15}
+      - {label: Never, score: 0}
+    conditions:
+      - {questionId: 1, anyOf: ["Yes"]}
+tiers:
+  version: 1
+  tiers:
+    - {name: Basic Cyber Insurance, minScore: 0, coverageLimit: 1000000}
+    - {name: Premium Cyber Insurance, minScore: 20, coverageLimit: 5000000}
+`
+
+func TestCatalogImportExportRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	os.WriteFile(path, []byte(bundleYAML), 0o644)
+
+	store := repositories.NewMemory()
+	h := handlers.New(repositories.From(store))
+
+	var out bytes.Buffer
+	if err := commands.Catalog(context.Background(), h, []string{"import", "-dry-run", path}, &out); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if !strings.Contains(out.String(), "+ question 2") || !strings.Contains(out.String(), "nothing written") {
+		t.Errorf("expected a dry-run diff, got:\n%s", out.String())
+	}
+	if qs, _ := store.AllQuestions(context.Background()); len(qs) != 0 {
+		t.Fatalf("dry run wrote %d questions", len(qs))
+	}
+
+	out.Reset()
+	if err := commands.Catalog(context.Background(), h, []string{"import", path}, &out); err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	out.Reset()
+	if err := commands.Catalog(context.Background(), h, []string{"import", path}, &out); err != nil || !strings.Contains(out.String(), "up to date") {
+		t.Errorf("expected a second import to change nothing, got %v:\n%s", err, out.String())
+	}
+
+	for _, format := range []string{"yaml", "json"} {
+		out.Reset()
+		if err := commands.Catalog(context.Background(), h, []string{"export", "-format", format}, &out); err != nil {
+			t.Fatalf("export %s: %v", format, err)
+		}
+		exported, err := commands.DecodeBundle(out.Bytes())
+		if err != nil {
+			t.Fatalf("decode %s: %v", format, err)
+		}
+		original, _ := commands.DecodeBundle([]byte(bundleYAML))
+		original.Questions[1].Options = []string{"Quarterly", "Never"}
+		if !reflect.DeepEqual(exported, original) {
+			t.Errorf("%s export does not round-trip:\n%s", format, out.String())
+		}
+	}
+}
+
+func TestCatalogImportDiff(t *testing.T) {
+	store := repositories.NewMemory()
+	store.SetParadigms([]models.Paradigm{{ID: 1, Name: "Threat", Position: 1}, {ID: 3, Name: "Legacy", Position: 2}})
+	store.SetQuestions([]models.Question{
+		{ID: 1, Paradigm: "1", Text: "Do you use MFA?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 5, Required: true, Position: 1},
+		{ID: 7, Paradigm: "3", Text: "Fax machine?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 1, Position: 2},
+	})
+	h := handlers.New(repositories.From(store))
+
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	os.WriteFile(path, []byte(bundleYAML), 0o644)
+	var out bytes.Buffer
+	if err := commands.Catalog(context.Background(), h, []string{"import", "-dry-run", path}, &out); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	for _, want := range []string{"~ question 1\n    weight: 5 -> 10", "- question 7", "- paradigm 3", "+ paradigm 2", "+ tiers 1"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the diff:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestCatalogImportRejectsInvalidBundle(t *testing.T) {
+	h := handlers.New(repositories.From(repositories.NewMemory()))
+	path := filepath.Join(t.TempDir(), "catalog.yaml")
+	os.WriteFile(path, []byte(strings.Replace(bundleYAML, "weight: 10", "weight: 1000", 1)), 0o644)
+
+	err := commands.Catalog(context.Background(), h, []string{"import", path}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "weight 1000") {
+		t.Errorf("expected the weight to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"cyber-go/pkg/db"
+)
+
+// Migrate implements `cyber-go migrate up|down|status`.
+func Migrate(conn *sql.DB, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down [-steps n]|status")
+	}
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	steps := fs.Int("steps", 1, "number of migrations to roll back (down only)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	m, err := db.NewMigrator(conn)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		for _, mig := range done {
+			fmt.Fprintf(out, "applied %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+	case "down":
+		if *steps < 1 {
+			return fmt.Errorf("-steps must be at least 1, got %d", *steps)
+		}
+		done, err := m.Down(ctx, *steps)
+		for _, mig := range done {
+			fmt.Fprintf(out, "rolled back %04d_%s\n", mig.Version, mig.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(out, "no migrations to roll back")
+		}
+		return err
+	case "status":
+		states, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range states {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = "applied " + s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
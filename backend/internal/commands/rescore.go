@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+)
+
+// Rescore implements `cyber-go rescore`: it replays the latest submission of
+// every applicant of the tenant of ctx against a candidate questionnaire and
+// tier table and prints the diff report.
+func Rescore(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rescore", flag.ContinueOnError)
+	version := fs.Int("questionnaire", -1, "questionnaire version to replay against (0 = live questions table, -1 = current)")
+	tiersFile := fs.String("tiers", "", "JSON file with a candidate tier table (default: current tiers)")
+	format := fs.String("format", "json", "report format: json or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var req handlers.RescoreRequest
+	if *version >= 0 {
+		req.QuestionnaireVersion = version
+	}
+	if *tiersFile != "" {
+		data, err := os.ReadFile(*tiersFile)
+		if err != nil {
+			return err
+		}
+		var tiers models.TierTable
+		if err := json.Unmarshal(data, &tiers); err != nil {
+			return fmt.Errorf("%s: %w", *tiersFile, err)
+		}
+		req.Tiers = &tiers
+	}
+
+	report, err := h.BuildRescoreReport(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "csv":
+		return controllers.WriteRescoreCSV(out, report)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// Tenant implements `cyber-go tenant create|list`.
+func Tenant(ctx context.Context, h *handlers.Handler, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("usage: tenant create [-name name] id | tenant list")
+	}
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("tenant create", flag.ContinueOnError)
+		name := fs.String("name", "", "display name (default: the id)")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if fs.NArg() != 1 {
+			return errors.New("usage: tenant create [-name name] id")
+		}
+		t := models.Tenant{ID: fs.Arg(0), Name: *name}
+		if t.Name == "" {
+			t.Name = t.ID
+		}
+		t, err := h.CreateTenant(ctx, t)
+		if errors.Is(err, repositories.ErrConflict) {
+			return fmt.Errorf("tenant %q already exists", t.ID)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "created tenant %s\n", t.ID)
+		return nil
+	case "list":
+		tenants, err := h.Tenants(ctx)
+		if err != nil {
+			return err
+		}
+		for _, t := range tenants {
+			fmt.Fprintf(out, "%s\t%s\t%s\n", t.ID, t.Name, t.CreatedAt.Format(time.RFC3339))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown tenant command %q", args[0])
+	}
+}
@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"cyber-go/internal/models"
+)
+
+// APIKeyPrefix starts every API key, so keys are told apart from other
+// bearer tokens and are easy to spot when leaked.
+const APIKeyPrefix = "cgk_"
+
+// APIKeyScopes are the scopes an API key may be granted. They are named
+// like the permissions they grant.
+var APIKeyScopes = []string{"read:questions", "submit", "read:results"}
+
+// NewAPIKey returns a random key id and the secret key to hand out for it,
+// "cgk_<id>_<secret>".
+func NewAPIKey() (id, key string, err error) {
+	idBytes := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	id = hex.EncodeToString(idBytes)
+	return id, APIKeyPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// ParseAPIKey splits a key into its id and secret. ok is false for strings
+// that are not API keys.
+func ParseAPIKey(key string) (id, secret string, ok bool) {
+	rest, ok := strings.CutPrefix(key, APIKeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	// Ids are hex, so the first underscore ends them; secrets may contain
+	// more.
+	id, secret, ok = strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+// HashAPIKey returns the hash stored for a key. Secrets are random, so a
+// plain SHA-256 is enough to keep a database leak from revealing keys.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateAPIKey checks a key before it is created.
+func ValidateAPIKey(k models.APIKey, now time.Time) error {
+	if strings.TrimSpace(k.Name) == "" {
+		return errors.New("API key name is required")
+	}
+	if len(k.Scopes) == 0 {
+		return errors.New("API key needs at least one scope")
+	}
+	for _, scope := range k.Scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return fmt.Errorf("unknown scope %q, expected one of %s", scope, strings.Join(APIKeyScopes, ", "))
+		}
+	}
+	if k.DailyQuota < 0 {
+		return errors.New("daily quota must not be negative")
+	}
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(now) {
+		return errors.New("API key would expire before it is created")
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package controllers_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestNewAPIKeyParses(t *testing.T) {
+	id, key, err := controllers.NewAPIKey()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(key, controllers.APIKeyPrefix) {
+		t.Errorf("expected the key to start with %s, got %s", controllers.APIKeyPrefix, key)
+	}
+	parsed, secret, ok := controllers.ParseAPIKey(key)
+	if !ok || parsed != id || secret == "" {
+		t.Errorf("expected %s to parse to id %s, got %q %q %v", key, id, parsed, secret, ok)
+	}
+	for _, s := range []string{"", "cgk_", "cgk_abc", "cgk__secret", "abc_def"} {
+		if _, _, ok := controllers.ParseAPIKey(s); ok {
+			t.Errorf("expected %q not to parse", s)
+		}
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	if err := controllers.ValidateAPIKey(models.APIKey{Name: "Broker", Scopes: []string{"submit", "read:results"}, ExpiresAt: &future}, now); err != nil {
+		t.Errorf("expected a valid key, got %v", err)
+	}
+
+	invalid := []models.APIKey{
+		{Name: " ", Scopes: []string{"submit"}},
+		{Name: "Broker"},
+		{Name: "Broker", Scopes: []string{"admin"}},
+		{Name: "Broker", Scopes: []string{"submit"}, DailyQuota: -1},
+		{Name: "Broker", Scopes: []string{"submit"}, ExpiresAt: &past},
+	}
+	for _, k := range invalid {
+		if err := controllers.ValidateAPIKey(k, now); err == nil {
+			t.Errorf("expected %+v to be rejected", k)
+		}
+	}
+}
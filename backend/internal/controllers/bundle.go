@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"cyber-go/internal/models"
+)
+
+// Catalog change kinds and actions reported by PlanImport.
+const (
+	ChangeCreate = "create"
+	ChangeUpdate = "update"
+	ChangeRetire = "retire"
+)
+
+// BuildBundle exports the active catalog. Positions follow list order and
+// revisions are left out, so an unchanged catalog exports identically.
+func BuildBundle(paradigms []models.Paradigm, questions []models.Question, tiers *models.TierTable) models.Bundle {
+	b := models.Bundle{Paradigms: []models.Paradigm{}, Questions: []models.Question{}, Tiers: tiers}
+	for _, p := range paradigms {
+		if !p.Retired {
+			p.Position, p.Revision = 0, 0
+			b.Paradigms = append(b.Paradigms, p)
+		}
+	}
+	for _, q := range questions {
+		if !q.Retired {
+			q.Position, q.Revision = 0, 0
+			b.Questions = append(b.Questions, q)
+		}
+	}
+	return b
+}
+
+// ValidateBundle checks a bundle as a whole: explicit, unique ids, valid
+// paradigms, questions and tiers, and questions assigned to paradigms in the
+// bundle.
+func ValidateBundle(b models.Bundle) error {
+	paradigms := map[string]bool{}
+	for _, p := range b.Paradigms {
+		if p.ID <= 0 {
+			return fmt.Errorf("paradigm %q needs a positive id", p.Name)
+		}
+		key := strconv.Itoa(p.ID)
+		if paradigms[key] {
+			return fmt.Errorf("duplicate paradigm id %d", p.ID)
+		}
+		paradigms[key] = true
+		if err := ValidateParadigm(p); err != nil {
+			return fmt.Errorf("paradigm %d: %w", p.ID, err)
+		}
+	}
+
+	questions := map[int]bool{}
+	for _, q := range b.Questions {
+		if q.ID <= 0 {
+			return fmt.Errorf("question %q needs a positive id", q.Text)
+		}
+		if questions[q.ID] {
+			return fmt.Errorf("duplicate question id %d", q.ID)
+		}
+		questions[q.ID] = true
+		if err := ValidateQuestion(q); err != nil {
+			return fmt.Errorf("question %d: %w", q.ID, err)
+		}
+		if !paradigms[q.Paradigm] {
+			return fmt.Errorf("question %d: unknown paradigm %q", q.ID, q.Paradigm)
+		}
+	}
+	if err := ValidateCatalog(b.Questions); err != nil {
+		return err
+	}
+
+	if b.Tiers != nil {
+		if err := ValidateTierTable(*b.Tiers); err != nil {
+			return fmt.Errorf("tiers: %w", err)
+		}
+	}
+	return nil
+}
+
+// PlanImport works out what importing b over the stored catalog changes.
+// Stored rows missing from the bundle are retired; rules may not depend on
+// questions that would be retired. Tiers are only added when they differ
+// from the current table, as the next version.
+func PlanImport(paradigms []models.Paradigm, questions []models.Question, tiers *models.TierTable,
+	rules []models.UnderwritingRule, b models.Bundle) (models.CatalogPlan, error) {
+	if err := ValidateBundle(b); err != nil {
+		return models.CatalogPlan{}, err
+	}
+	var plan models.CatalogPlan
+
+	storedParadigms := map[int]models.Paradigm{}
+	for _, p := range paradigms {
+		storedParadigms[p.ID] = p
+	}
+	keep := map[int]bool{}
+	for i, p := range b.Paradigms {
+		p.Position, p.Retired = i+1, false
+		keep[p.ID] = true
+		stored, ok := storedParadigms[p.ID]
+		if !ok {
+			p.Revision = 0
+			plan.Paradigms = append(plan.Paradigms, p)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "paradigm", ID: p.ID, Action: ChangeCreate, Fields: fieldChanges(models.Paradigm{}, p)})
+			continue
+		}
+		p.Revision = stored.Revision
+		if fields := fieldChanges(stored, p); len(fields) > 0 {
+			plan.Paradigms = append(plan.Paradigms, p)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "paradigm", ID: p.ID, Action: ChangeUpdate, Fields: fields})
+		}
+	}
+	for _, p := range paradigms {
+		if !keep[p.ID] && !p.Retired {
+			p.Retired = true
+			plan.Paradigms = append(plan.Paradigms, p)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "paradigm", ID: p.ID, Action: ChangeRetire})
+		}
+	}
+
+	storedQuestions := map[int]models.Question{}
+	for _, q := range questions {
+		storedQuestions[q.ID] = q
+	}
+	keep = map[int]bool{}
+	for i, q := range b.Questions {
+		q.Position, q.Retired = i+1, false
+		if len(q.Choices) > 0 {
+			// Stored questions carry their choice labels as options too.
+			q.Options = make([]string, len(q.Choices))
+			for j, c := range q.Choices {
+				q.Options[j] = c.Label
+			}
+		}
+		keep[q.ID] = true
+		stored, ok := storedQuestions[q.ID]
+		if !ok {
+			q.Revision = 0
+			plan.Questions = append(plan.Questions, q)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "question", ID: q.ID, Action: ChangeCreate, Fields: fieldChanges(models.Question{}, q)})
+			continue
+		}
+		q.Revision = stored.Revision
+		if fields := fieldChanges(stored, q); len(fields) > 0 {
+			plan.Questions = append(plan.Questions, q)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "question", ID: q.ID, Action: ChangeUpdate, Fields: fields})
+		}
+	}
+	for _, q := range questions {
+		if !keep[q.ID] && !q.Retired {
+			for _, rule := range rules {
+				for _, c := range rule.When {
+					if c.QuestionID == q.ID {
+						return models.CatalogPlan{}, fmt.Errorf("underwriting rule %q depends on question %d, which is not in the bundle", rule.ID, q.ID)
+					}
+				}
+			}
+			q.Retired = true
+			plan.Questions = append(plan.Questions, q)
+			plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "question", ID: q.ID, Action: ChangeRetire})
+		}
+	}
+
+	if b.Tiers != nil && (tiers == nil || !reflect.DeepEqual(tiers.Tiers, b.Tiers.Tiers)) {
+		next := models.TierTable{Version: 1, Tiers: b.Tiers.Tiers}
+		old := models.TierTable{}
+		if tiers != nil {
+			next.Version = tiers.Version + 1
+			old = *tiers
+		}
+		plan.Tiers = &next
+		plan.Changes = append(plan.Changes, models.CatalogChange{Kind: "tiers", ID: next.Version, Action: ChangeCreate,
+			Fields: fieldChanges(models.TierTable{Tiers: old.Tiers}, models.TierTable{Tiers: next.Tiers})})
+	}
+	return plan, nil
+}
+
+// fieldChanges compares two values field by field through their JSON
+// encoding, ignoring revisions. Fields left out by omitempty render as "".
+func fieldChanges(old, new interface{}) []models.FieldChange {
+	before, after := jsonFields(old), jsonFields(new)
+	delete(before, "revision")
+	delete(after, "revision")
+
+	names := map[string]bool{}
+	for k := range before {
+		names[k] = true
+	}
+	for k := range after {
+		names[k] = true
+	}
+	var changes []models.FieldChange
+	for name := range names {
+		if before[name] != after[name] {
+			changes = append(changes, models.FieldChange{Field: name, Old: before[name], New: after[name]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func jsonFields(v interface{}) map[string]string {
+	data, _ := json.Marshal(v)
+	var raw map[string]json.RawMessage
+	json.Unmarshal(data, &raw)
+
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		if s := string(v); s != "null" && s != `""` && s != "0" && s != "false" && s != "[]" {
+			fields[k] = s
+		}
+	}
+	return fields
+}
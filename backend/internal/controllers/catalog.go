@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cyber-go/internal/models"
+)
+
+// MaxQuestionWeight bounds the weight an admin can give a single question.
+const MaxQuestionWeight = 100
+
+// ValidateParadigm checks an edited paradigm before it is stored.
+func ValidateParadigm(p models.Paradigm) error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("paradigm name is required")
+	}
+	if p.Position < 0 {
+		return errors.New("position cannot be negative")
+	}
+	return nil
+}
+
+// ValidateQuestion checks an edited question on its own: the text, a known
+// selector, a weight in range and, for choice selectors, a non-empty list of
+// distinct options. Conditions are checked against the whole catalog by
+// ValidateCatalog.
+func ValidateQuestion(q models.Question) error {
+	if strings.TrimSpace(q.Text) == "" {
+		return errors.New("question text is required")
+	}
+	if q.Paradigm == "" {
+		return errors.New("question must be assigned to a paradigm")
+	}
+	if _, ok := ScorerFor(q.Selector); !ok {
+		return fmt.Errorf("unknown selector %q", q.Selector)
+	}
+	if q.Weight < 0 || q.Weight > MaxQuestionWeight {
+		return fmt.Errorf("weight %d is outside 0-%d", q.Weight, MaxQuestionWeight)
+	}
+	if q.Position < 0 {
+		return errors.New("position cannot be negative")
+	}
+
+	if q.Selector == "number" || q.Selector == "date" {
+		return nil
+	}
+	if len(q.Options) == 0 && len(q.Choices) == 0 {
+		return fmt.Errorf("%s questions need at least one option", q.Selector)
+	}
+	seen := make(map[string]bool, len(q.Options))
+	for _, o := range q.Options {
+		if strings.TrimSpace(o) == "" {
+			return errors.New("option with an empty label")
+		}
+		if seen[o] {
+			return fmt.Errorf("duplicate option %q", o)
+		}
+		seen[o] = true
+	}
+	return ValidateChoices(q)
+}
@@ -0,0 +1,47 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestValidateQuestion(t *testing.T) {
+	valid := models.Question{Paradigm: "1", Text: "Do you use MFA?", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}}
+	if err := controllers.ValidateQuestion(valid); err != nil {
+		t.Fatalf("expected a valid question, got %v", err)
+	}
+
+	cases := map[string]func(q *models.Question){
+		"unknown selector": func(q *models.Question) { q.Selector = "slider" },
+		"weight too high":  func(q *models.Question) { q.Weight = controllers.MaxQuestionWeight + 1 },
+		"negative weight":  func(q *models.Question) { q.Weight = -1 },
+		"no options":       func(q *models.Question) { q.Options = nil },
+		"empty option":     func(q *models.Question) { q.Options = []string{"Yes", " "} },
+		"duplicate option": func(q *models.Question) { q.Options = []string{"Yes", "Yes"} },
+		"no paradigm":      func(q *models.Question) { q.Paradigm = "" },
+		"no text":          func(q *models.Question) { q.Text = "" },
+	}
+	for name, mutate := range cases {
+		q := valid
+		mutate(&q)
+		if err := controllers.ValidateQuestion(q); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	number := models.Question{Paradigm: "1", Text: "How many staff?", Selector: "number", Weight: 5}
+	if err := controllers.ValidateQuestion(number); err != nil {
+		t.Errorf("number questions do not need options, got %v", err)
+	}
+}
+
+func TestValidateParadigm(t *testing.T) {
+	if err := controllers.ValidateParadigm(models.Paradigm{Name: "Threat"}); err != nil {
+		t.Errorf("expected a valid paradigm, got %v", err)
+	}
+	if err := controllers.ValidateParadigm(models.Paradigm{Name: "  "}); err == nil {
+		t.Error("expected a blank name to be rejected")
+	}
+}
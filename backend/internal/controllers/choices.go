@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"cyber-go/internal/models"
+)
+
+// ValidateChoices checks a question's explicit option scores and limits.
+func ValidateChoices(q models.Question) error {
+	labels := make(map[string]bool, len(q.Choices))
+	for _, c := range q.Choices {
+		if c.Label == "" {
+			return errors.New("option with an empty label")
+		}
+		if labels[c.Label] {
+			return fmt.Errorf("duplicate option %q", c.Label)
+		}
+		labels[c.Label] = true
+		if c.Score < 0 {
+			return fmt.Errorf("option %q has a negative score; use the negative flag instead", c.Label)
+		}
+	}
+	if q.MinSelections < 0 || q.MaxSelections < 0 {
+		return errors.New("selection limits cannot be negative")
+	}
+	if q.MaxSelections > 0 && q.MinSelections > q.MaxSelections {
+		return fmt.Errorf("minSelections %d is above maxSelections %d", q.MinSelections, q.MaxSelections)
+	}
+	return nil
+}
+
+func findChoice(q models.Question, label string) (models.Option, bool) {
+	for _, c := range q.Choices {
+		if c.Label == label {
+			return c, true
+		}
+	}
+	return models.Option{}, false
+}
+
+// choicePoints is what selecting c adds to, or with the negative flag
+// deducts from, a question's points.
+func choicePoints(c models.Option) int {
+	switch {
+	case c.DontKnow:
+		return 0
+	case c.Negative:
+		return -c.Score
+	default:
+		return c.Score
+	}
+}
+
+// singleChoiceMax is the best score a radio or dropdown answer can earn.
+func singleChoiceMax(q models.Question) int {
+	max := 0
+	for _, c := range q.Choices {
+		if p := choicePoints(c); p > max {
+			max = p
+		}
+	}
+	return max
+}
+
+// multiChoiceMax is the best score a checkbox answer can earn: either the
+// best exclusive option, or the best allowed combination of the others.
+func multiChoiceMax(q models.Question) int {
+	var combinable []int
+	exclusiveMax := 0
+	for _, c := range q.Choices {
+		p := choicePoints(c)
+		if p <= 0 {
+			continue
+		}
+		if c.Exclusive {
+			if p > exclusiveMax {
+				exclusiveMax = p
+			}
+			continue
+		}
+		combinable = append(combinable, p)
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(combinable)))
+	if q.MaxSelections > 0 && len(combinable) > q.MaxSelections {
+		combinable = combinable[:q.MaxSelections]
+	}
+	sum := 0
+	for _, p := range combinable {
+		sum += p
+	}
+	if exclusiveMax > sum {
+		return exclusiveMax
+	}
+	return sum
+}
+
+// checkSelections enforces selection limits, duplicates and exclusive options.
+func checkSelections(q models.Question, selected []string) error {
+	if q.MinSelections > 0 && len(selected) < q.MinSelections {
+		return fmt.Errorf("%w: select at least %d options", ErrOutOfRange, q.MinSelections)
+	}
+	if q.MaxSelections > 0 && len(selected) > q.MaxSelections {
+		return fmt.Errorf("%w: select at most %d options", ErrOutOfRange, q.MaxSelections)
+	}
+	seen := make(map[string]bool, len(selected))
+	for _, s := range selected {
+		if seen[s] {
+			return fmt.Errorf("%w: %q selected more than once", ErrInvalidOption, s)
+		}
+		seen[s] = true
+		if c, ok := findChoice(q, s); ok && c.Exclusive && len(selected) > 1 {
+			return fmt.Errorf("%w: %q cannot be combined with other options", ErrInvalidOption, s)
+		}
+	}
+	return nil
+}
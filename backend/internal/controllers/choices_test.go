@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func backupQuestion() models.Question {
+	q := models.Question{ID: 1, Selector: "checkbox", Weight: 10, MaxSelections: 2, Choices: []models.Option{
+		{Label: "Offline backups", Score: 6},
+		{Label: "Cloud backups", Score: 3},
+		{Label: "Tape", Score: 2},
+		{Label: "Shared admin account", Score: 4, Negative: true},
+		{Label: "None of the above", Exclusive: true},
+		{Label: "Don't know", Score: 5, DontKnow: true, Exclusive: true},
+	}}
+	for _, c := range q.Choices {
+		q.Options = append(q.Options, c.Label)
+	}
+	return q
+}
+
+func TestCheckboxChoiceScores(t *testing.T) {
+	q := backupQuestion()
+	cases := []struct {
+		answer []string
+		want   int
+	}{
+		{[]string{"Offline backups", "Cloud backups"}, 9},
+		{[]string{"Tape"}, 2},
+		{[]string{"Tape", "Shared admin account"}, 0},
+		{[]string{"Offline backups", "Shared admin account"}, 2},
+		{[]string{"None of the above"}, 0},
+		{[]string{"Don't know"}, 0},
+	}
+	for _, c := range cases {
+		res := controllers.EvaluateAnswers(map[int]interface{}{1: c.answer}, []models.Question{q})
+		if res.TotalScore != c.want {
+			t.Errorf("%v: expected %d, got %d", c.answer, c.want, res.TotalScore)
+		}
+	}
+
+	res := controllers.EvaluateAnswers(map[int]interface{}{}, []models.Question{q})
+	if res.Paradigms[0].MaxPoints != 9 {
+		t.Errorf("expected the max to respect maxSelections, got %d", res.Paradigms[0].MaxPoints)
+	}
+}
+
+func TestCheckboxSelectionRules(t *testing.T) {
+	q := backupQuestion()
+	q.MinSelections = 1
+	cases := []struct {
+		answer []string
+		code   string
+	}{
+		{[]string{}, controllers.CodeOutOfRange},
+		{[]string{"Offline backups", "Cloud backups", "Tape"}, controllers.CodeOutOfRange},
+		{[]string{"None of the above", "Tape"}, controllers.CodeInvalidOption},
+		{[]string{"Tape", "Tape"}, controllers.CodeInvalidOption},
+	}
+	for _, c := range cases {
+		errs := controllers.ValidateAnswers(map[int]interface{}{1: c.answer}, []models.Question{q})
+		if len(errs) != 1 || errs[0].Code != c.code {
+			t.Errorf("%v: expected %s, got %+v", c.answer, c.code, errs)
+		}
+	}
+}
+
+func TestDropdownChoiceScoresIgnoreOrder(t *testing.T) {
+	q := models.Question{ID: 1, Selector: "dropdown", Weight: 10, Choices: []models.Option{
+		{Label: "Daily", Score: 10},
+		{Label: "Never", Score: 0},
+		{Label: "Weekly", Score: 6},
+	}, Options: []string{"Daily", "Never", "Weekly"}}
+
+	res := controllers.EvaluateAnswers(map[int]interface{}{1: "Never"}, []models.Question{q})
+	if res.TotalScore != 0 {
+		t.Errorf("expected the last-but-one option to score 0, got %d", res.TotalScore)
+	}
+	res = controllers.EvaluateAnswers(map[int]interface{}{1: "Daily"}, []models.Question{q})
+	if res.TotalScore != 10 || res.Paradigms[0].MaxPoints != 10 {
+		t.Errorf("expected 10 of 10, got %d of %d", res.TotalScore, res.Paradigms[0].MaxPoints)
+	}
+}
+
+func TestValidateChoices(t *testing.T) {
+	cases := map[string]models.Question{
+		"duplicate label": {ID: 1, Choices: []models.Option{{Label: "A"}, {Label: "A"}}},
+		"empty label":     {ID: 1, Choices: []models.Option{{Label: ""}}},
+		"negative score":  {ID: 1, Choices: []models.Option{{Label: "A", Score: -2}}},
+		"min above max":   {ID: 1, MinSelections: 3, MaxSelections: 2},
+	}
+	for name, q := range cases {
+		if err := controllers.ValidateChoices(q); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
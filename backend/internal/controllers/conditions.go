@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"fmt"
+
+	"cyber-go/internal/models"
+)
+
+// ValidateCatalog checks a freshly loaded question catalog: option scores and
+// scoring curves must be usable and conditions must not dangle or form cycles.
+func ValidateCatalog(questions []models.Question) error {
+	for _, q := range questions {
+		if err := ValidateChoices(q); err != nil {
+			return fmt.Errorf("question %d: %w", q.ID, err)
+		}
+		if q.Curve != nil {
+			if err := ValidateCurve(*q.Curve); err != nil {
+				return fmt.Errorf("question %d: %w", q.ID, err)
+			}
+		} else if q.Selector == "number" || q.Selector == "date" {
+			return fmt.Errorf("question %d: %s questions need a scoring curve", q.ID, q.Selector)
+		}
+	}
+	return ValidateConditions(questions)
+}
+
+// ValidateConditions checks that conditions only reference questions in the
+// catalog and that no question depends on itself, directly or indirectly.
+func ValidateConditions(questions []models.Question) error {
+	byID := make(map[int]models.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[int]int, len(questions))
+
+	var visit func(id int, path []int) error
+	visit = func(id int, path []int) error {
+		switch state[id] {
+		case visiting:
+			return fmt.Errorf("condition cycle: %v", append(path, id))
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		for _, c := range byID[id].Conditions {
+			if _, ok := byID[c.QuestionID]; !ok {
+				return fmt.Errorf("question %d has a condition on unknown question %d", id, c.QuestionID)
+			}
+			if err := visit(c.QuestionID, append(path, id)); err != nil {
+				return err
+			}
+		}
+		state[id] = done
+		return nil
+	}
+
+	for _, q := range questions {
+		if err := visit(q.ID, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VisibleQuestions returns, in catalog order, the questions whose conditions
+// hold for the given answers. A condition on a hidden question never holds.
+func VisibleQuestions(questions []models.Question, answers map[int]interface{}) []models.Question {
+	visible := visibility(questions, answers)
+	out := make([]models.Question, 0, len(questions))
+	for _, q := range questions {
+		if visible[q.ID] {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// visibility resolves every question's visibility. Conditions are assumed to
+// have passed ValidateConditions; a cycle resolves to hidden.
+func visibility(questions []models.Question, answers map[int]interface{}) map[int]bool {
+	byID := make(map[int]models.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+
+	visible := make(map[int]bool, len(questions))
+	resolving := make(map[int]bool)
+
+	var resolve func(id int) bool
+	resolve = func(id int) bool {
+		if v, ok := visible[id]; ok {
+			return v
+		}
+		q, ok := byID[id]
+		if !ok || resolving[id] {
+			return false
+		}
+		resolving[id] = true
+		v := true
+		for _, c := range q.Conditions {
+			if !resolve(c.QuestionID) || !conditionHolds(c, answers[c.QuestionID]) {
+				v = false
+				break
+			}
+		}
+		delete(resolving, id)
+		visible[id] = v
+		return v
+	}
+
+	for _, q := range questions {
+		resolve(q.ID)
+	}
+	return visible
+}
+
+// conditionHolds reports whether a single or multi-valued answer contains
+// one of the condition's values.
+func conditionHolds(c models.Condition, ans interface{}) bool {
+	var given []string
+	if s, ok := ans.(string); ok {
+		given = []string{s}
+	} else if list, err := toStringSlice(ans); err == nil {
+		given = list
+	}
+	for _, g := range given {
+		for _, want := range c.AnyOf {
+			if g == want {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func conditionalCatalog() []models.Question {
+	return []models.Question{
+		{ID: 1, Paradigm: "Threat", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true},
+		{ID: 2, Paradigm: "Threat", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true,
+			Conditions: []models.Condition{{QuestionID: 1, AnyOf: []string{"Yes"}}}},
+		{ID: 3, Paradigm: "Threat", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true,
+			Conditions: []models.Condition{{QuestionID: 2, AnyOf: []string{"Yes"}}}},
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	if err := controllers.ValidateConditions(conditionalCatalog()); err != nil {
+		t.Fatalf("expected a valid catalog, got %v", err)
+	}
+
+	cyclic := conditionalCatalog()
+	cyclic[0].Conditions = []models.Condition{{QuestionID: 3, AnyOf: []string{"No"}}}
+	if err := controllers.ValidateConditions(cyclic); err == nil {
+		t.Error("expected a cycle to be rejected")
+	}
+
+	dangling := conditionalCatalog()
+	dangling[1].Conditions = []models.Condition{{QuestionID: 42, AnyOf: []string{"Yes"}}}
+	if err := controllers.ValidateConditions(dangling); err == nil {
+		t.Error("expected a condition on an unknown question to be rejected")
+	}
+}
+
+func TestVisibleQuestionsFollowsChains(t *testing.T) {
+	catalog := conditionalCatalog()
+
+	cases := []struct {
+		answers map[int]interface{}
+		want    int
+	}{
+		{map[int]interface{}{}, 1},
+		{map[int]interface{}{1: "No", 2: "Yes"}, 1},
+		{map[int]interface{}{1: "Yes"}, 2},
+		{map[int]interface{}{1: "Yes", 2: "Yes"}, 3},
+	}
+	for _, c := range cases {
+		if got := controllers.VisibleQuestions(catalog, c.answers); len(got) != c.want {
+			t.Errorf("answers %v: expected %d visible questions, got %d", c.answers, c.want, len(got))
+		}
+	}
+}
+
+func TestHiddenQuestionsAreNotScoredOrRequired(t *testing.T) {
+	catalog := conditionalCatalog()
+	answers := map[int]interface{}{1: "No", 3: "Yes"}
+
+	if errs := controllers.ValidateAnswers(answers, catalog); len(errs) != 0 {
+		t.Fatalf("expected hidden questions to be optional, got %+v", errs)
+	}
+
+	res := controllers.EvaluateAnswers(answers, catalog)
+	if res.TotalScore != 0 {
+		t.Errorf("expected the hidden answer to be ignored, got %d", res.TotalScore)
+	}
+	if res.Paradigms[0].MaxPoints != 10 {
+		t.Errorf("expected hidden questions to be excluded from the maximum, got %d", res.Paradigms[0].MaxPoints)
+	}
+}
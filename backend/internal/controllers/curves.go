@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"cyber-go/internal/models"
+)
+
+// DateLayout is the format expected for "date" answers.
+const DateLayout = "2006-01-02"
+
+// now is the reference point for date answers.
+var now = time.Now
+
+// ValidateCurve checks that a curve can be evaluated.
+func ValidateCurve(c models.Curve) error {
+	switch c.Kind {
+	case "steps":
+		if len(c.Steps) == 0 {
+			return errors.New("steps curve has no steps")
+		}
+		for i, step := range c.Steps {
+			if step.Fraction < 0 || step.Fraction > 1 {
+				return fmt.Errorf("step %d fraction %v must be between 0 and 1", i, step.Fraction)
+			}
+			if i > 0 && step.From <= c.Steps[i-1].From {
+				return fmt.Errorf("step %d must start above %v", i, c.Steps[i-1].From)
+			}
+		}
+	case "linear":
+		if c.From == c.To {
+			return errors.New("linear curve needs different from and to values")
+		}
+	case "decay":
+		if c.HalfLifeDays <= 0 {
+			return errors.New("decay curve needs a positive halfLifeDays")
+		}
+	default:
+		return fmt.Errorf("unknown curve kind %q", c.Kind)
+	}
+	return nil
+}
+
+// curveFraction evaluates the curve at x and clamps the result to [0, 1].
+func curveFraction(c models.Curve, x float64) float64 {
+	f := 0.0
+	switch c.Kind {
+	case "steps":
+		for _, step := range c.Steps {
+			if x < step.From {
+				break
+			}
+			f = step.Fraction
+		}
+	case "linear":
+		f = (x - c.From) / (c.To - c.From)
+	case "decay":
+		f = math.Pow(0.5, math.Max(x, 0)/c.HalfLifeDays)
+	}
+	return math.Min(math.Max(f, 0), 1)
+}
+
+func curvePoints(q models.Question, x float64) int {
+	return int(math.Round(float64(q.Weight) * curveFraction(*q.Curve, x)))
+}
+
+// toNumber accepts the numeric types produced by encoding/json and Go callers.
+func toNumber(ans interface{}) (float64, error) {
+	switch v := ans.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("%w: expected a number, got %T", ErrWrongType, ans)
+	}
+}
+
+// ageInDays parses a date answer and returns how many whole days ago it was.
+func ageInDays(ans interface{}) (float64, error) {
+	s, ok := ans.(string)
+	if !ok {
+		return 0, fmt.Errorf("%w: expected a date string, got %T", ErrWrongType, ans)
+	}
+	d, err := time.Parse(DateLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: expected a date as YYYY-MM-DD, got %q", ErrWrongType, s)
+	}
+	y, m, day := now().Date()
+	today := time.Date(y, m, day, 0, 0, 0, 0, time.UTC)
+	age := math.Floor(today.Sub(d).Hours() / 24)
+	if age < 0 {
+		return 0, fmt.Errorf("%w: date %s is in the future", ErrOutOfRange, s)
+	}
+	return age, nil
+}
+
+// numberScorer scores a numeric answer along the question's curve.
+type numberScorer struct{}
+
+func (numberScorer) Validate(q models.Question, ans interface{}) error {
+	if q.Curve == nil {
+		return fmt.Errorf("question %d has no scoring curve", q.ID)
+	}
+	_, err := toNumber(ans)
+	return err
+}
+
+func (numberScorer) Score(q models.Question, ans interface{}) int {
+	x, _ := toNumber(ans)
+	return curvePoints(q, x)
+}
+
+func (numberScorer) MaxScore(q models.Question) int { return q.Weight }
+
+// dateScorer scores a date answer by its age in days along the question's curve.
+type dateScorer struct{}
+
+func (dateScorer) Validate(q models.Question, ans interface{}) error {
+	if q.Curve == nil {
+		return fmt.Errorf("question %d has no scoring curve", q.ID)
+	}
+	_, err := ageInDays(ans)
+	return err
+}
+
+func (dateScorer) Score(q models.Question, ans interface{}) int {
+	age, _ := ageInDays(ans)
+	return curvePoints(q, age)
+}
+
+func (dateScorer) MaxScore(q models.Question) int { return q.Weight }
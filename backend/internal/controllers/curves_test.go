@@ -0,0 +1,92 @@
+package controllers_test
+
+import (
+	"testing"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func daysAgo(n int) string {
+	return time.Now().AddDate(0, 0, -n).Format(controllers.DateLayout)
+}
+
+func TestNumberCurves(t *testing.T) {
+	steps := &models.Curve{Kind: "steps", Steps: []models.CurveStep{
+		{From: 0, Fraction: 0},
+		{From: 50, Fraction: 0.5},
+		{From: 90, Fraction: 1},
+	}}
+	linear := &models.Curve{Kind: "linear", From: 0, To: 100}
+
+	cases := []struct {
+		curve *models.Curve
+		value interface{}
+		want  int
+	}{
+		{steps, 10.0, 0},
+		{steps, 75.0, 5},
+		{steps, 95, 10},
+		{linear, 25.0, 3},
+		{linear, 150.0, 10},
+		{linear, -5.0, 0},
+	}
+	for _, c := range cases {
+		q := models.Question{ID: 1, Selector: "number", Weight: 10, Curve: c.curve}
+		res := controllers.EvaluateAnswers(map[int]interface{}{1: c.value}, []models.Question{q})
+		if res.TotalScore != c.want {
+			t.Errorf("%s curve at %v: expected %d, got %d", c.curve.Kind, c.value, c.want, res.TotalScore)
+		}
+	}
+}
+
+func TestDateDecayCurve(t *testing.T) {
+	q := models.Question{ID: 1, Selector: "date", Weight: 20,
+		Curve: &models.Curve{Kind: "decay", HalfLifeDays: 180}}
+
+	cases := map[string]int{
+		daysAgo(0):   20,
+		daysAgo(180): 10,
+		daysAgo(360): 5,
+	}
+	for date, want := range cases {
+		res := controllers.EvaluateAnswers(map[int]interface{}{1: date}, []models.Question{q})
+		if res.TotalScore != want {
+			t.Errorf("date %s: expected %d, got %d", date, want, res.TotalScore)
+		}
+	}
+}
+
+func TestDateValidation(t *testing.T) {
+	q := models.Question{ID: 1, Selector: "date", Weight: 20,
+		Curve: &models.Curve{Kind: "decay", HalfLifeDays: 180}}
+
+	cases := map[interface{}]string{
+		"last spring": controllers.CodeWrongType,
+		12.0:          controllers.CodeWrongType,
+		daysAgo(-30):  controllers.CodeOutOfRange,
+	}
+	for ans, code := range cases {
+		errs := controllers.ValidateAnswers(map[int]interface{}{1: ans}, []models.Question{q})
+		if len(errs) != 1 || errs[0].Code != code {
+			t.Errorf("answer %v: expected %s, got %+v", ans, code, errs)
+		}
+	}
+}
+
+func TestValidateCatalogCurves(t *testing.T) {
+	cases := map[string]models.Question{
+		"missing curve":  {ID: 1, Selector: "number", Weight: 10},
+		"unknown kind":   {ID: 1, Selector: "number", Weight: 10, Curve: &models.Curve{Kind: "cubic"}},
+		"empty steps":    {ID: 1, Selector: "number", Weight: 10, Curve: &models.Curve{Kind: "steps"}},
+		"flat linear":    {ID: 1, Selector: "number", Weight: 10, Curve: &models.Curve{Kind: "linear", From: 5, To: 5}},
+		"no half-life":   {ID: 1, Selector: "date", Weight: 10, Curve: &models.Curve{Kind: "decay"}},
+		"fraction above": {ID: 1, Selector: "number", Weight: 10, Curve: &models.Curve{Kind: "steps", Steps: []models.CurveStep{{From: 0, Fraction: 2}}}},
+	}
+	for name, q := range cases {
+		if err := controllers.ValidateCatalog([]models.Question{q}); err == nil {
+			t.Errorf("%s: expected the catalog to be rejected", name)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package controllers
+
+// UnregisterScorer removes the scorer of a selector, so tests registering
+// their own scorers leave the registry as they found it.
+func UnregisterScorer(selector string) {
+	scorers.Lock()
+	delete(scorers.data, selector)
+	scorers.Unlock()
+}
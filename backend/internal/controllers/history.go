@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"math"
+
+	"cyber-go/internal/models"
+)
+
+// AssessmentHistory turns submissions, oldest first, into history entries
+// that each carry the change from the previous assessment.
+func AssessmentHistory(subs []models.Submission) []models.Assessment {
+	history := make([]models.Assessment, 0, len(subs))
+	for i, sub := range subs {
+		a := models.Assessment{
+			SubmissionID:         sub.ID,
+			QuestionnaireVersion: sub.QuestionnaireVersion,
+			TotalScore:           sub.Result.TotalScore,
+			Policy:               sub.Result.Policy,
+			Decision:             sub.Result.Decision.Outcome,
+			SubmittedAt:          sub.CreatedAt,
+		}
+		if i > 0 {
+			a.Delta = assessmentDelta(subs[i-1].Result, sub.Result)
+		}
+		history = append(history, a)
+	}
+	return history
+}
+
+func assessmentDelta(prev, cur models.Result) *models.AssessmentDelta {
+	before := make(map[string]models.ParadigmScore, len(prev.Paradigms))
+	for _, ps := range prev.Paradigms {
+		before[ps.Paradigm] = ps
+	}
+
+	delta := &models.AssessmentDelta{
+		Score:          cur.TotalScore - prev.TotalScore,
+		PreviousPolicy: prev.Policy,
+		PolicyChanged:  cur.Policy != prev.Policy,
+		Paradigms:      []models.ParadigmDelta{},
+	}
+	for _, ps := range cur.Paradigms {
+		old := before[ps.Paradigm]
+		delta.Paradigms = append(delta.Paradigms, models.ParadigmDelta{
+			Paradigm:   ps.Paradigm,
+			Points:     ps.Points - old.Points,
+			Percentage: math.Round((ps.Percentage-old.Percentage)*100) / 100,
+		})
+	}
+	return delta
+}
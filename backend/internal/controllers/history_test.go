@@ -0,0 +1,42 @@
+package controllers_test
+
+import (
+	"testing"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestAssessmentHistoryDeltas(t *testing.T) {
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	subs := []models.Submission{
+		{ID: "a", CreatedAt: day, Result: models.Result{TotalScore: 15, Policy: "Basic Cyber Insurance",
+			Paradigms: []models.ParadigmScore{{Paradigm: "Threat", Points: 15, Percentage: 30}}}},
+		{ID: "b", CreatedAt: day.AddDate(0, 1, 0), Result: models.Result{TotalScore: 25, Policy: "Standard Cyber Insurance",
+			Paradigms: []models.ParadigmScore{{Paradigm: "Threat", Points: 20, Percentage: 40}, {Paradigm: "Vulnerability", Points: 5, Percentage: 50}}}},
+		{ID: "c", CreatedAt: day.AddDate(0, 2, 0), Result: models.Result{TotalScore: 22, Policy: "Standard Cyber Insurance",
+			Paradigms: []models.ParadigmScore{{Paradigm: "Threat", Points: 17, Percentage: 34}, {Paradigm: "Vulnerability", Points: 5, Percentage: 50}}}},
+	}
+
+	history := controllers.AssessmentHistory(subs)
+	if len(history) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(history))
+	}
+	if history[0].Delta != nil {
+		t.Errorf("the first assessment has nothing to compare with, got %+v", history[0].Delta)
+	}
+
+	d := history[1].Delta
+	if d.Score != 10 || !d.PolicyChanged || d.PreviousPolicy != "Basic Cyber Insurance" {
+		t.Errorf("unexpected delta: %+v", d)
+	}
+	if d.Paradigms[1].Points != 5 || d.Paradigms[1].Percentage != 50 {
+		t.Errorf("a new paradigm should compare against zero, got %+v", d.Paradigms[1])
+	}
+
+	d = history[2].Delta
+	if d.Score != -3 || d.PolicyChanged || d.Paradigms[0].Percentage != -6 {
+		t.Errorf("unexpected delta: %+v", d)
+	}
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"cyber-go/internal/models"
+)
+
+// DefaultTierTable is used until a table is loaded from the database.
+var DefaultTierTable = models.TierTable{
+	Version: 0,
+	Tiers: []models.PolicyTier{
+		{Name: "Basic Cyber Insurance", MinScore: 0},
+		{Name: "Standard Cyber Insurance", MinScore: 20},
+		{Name: "Premium Cyber Insurance", MinScore: 50},
+	},
+}
+
+var tierTable = struct {
+	sync.RWMutex
+	data models.TierTable
+}{data: DefaultTierTable}
+
+// ValidateTierTable checks that tiers start at zero, have unique non-empty
+// names and strictly increasing thresholds.
+func ValidateTierTable(t models.TierTable) error {
+	if len(t.Tiers) == 0 {
+		return errors.New("tier table has no tiers")
+	}
+	if t.Tiers[0].MinScore != 0 {
+		return fmt.Errorf("first tier %q must start at score 0, got %d", t.Tiers[0].Name, t.Tiers[0].MinScore)
+	}
+	names := make(map[string]bool)
+	for i, tier := range t.Tiers {
+		if tier.Name == "" {
+			return fmt.Errorf("tier %d has no name", i)
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("duplicate tier name %q", tier.Name)
+		}
+		names[tier.Name] = true
+		if tier.CoverageLimit < 0 {
+			return fmt.Errorf("tier %q has a negative coverage limit", tier.Name)
+		}
+		if i > 0 && tier.MinScore <= t.Tiers[i-1].MinScore {
+			return fmt.Errorf("tier %q threshold %d must be greater than %d", tier.Name, tier.MinScore, t.Tiers[i-1].MinScore)
+		}
+	}
+	return nil
+}
+
+// SetTierTable validates t and makes it the table used for scoring.
+func SetTierTable(t models.TierTable) error {
+	if err := ValidateTierTable(t); err != nil {
+		return err
+	}
+	tierTable.Lock()
+	tierTable.data = t
+	tierTable.Unlock()
+	return nil
+}
+
+// CurrentTierTable returns the table used for scoring.
+func CurrentTierTable() models.TierTable {
+	tierTable.RLock()
+	defer tierTable.RUnlock()
+	return tierTable.data
+}
+
+// PolicyFor returns the name of the highest tier whose threshold the score reaches.
+func PolicyFor(t models.TierTable, totalScore int) string {
+	return tierFor(t, totalScore).Name
+}
+
+// tierFor returns the highest tier whose threshold the score reaches, or the
+// lowest tier if it reaches none.
+func tierFor(t models.TierTable, totalScore int) models.PolicyTier {
+	var tier models.PolicyTier
+	for _, candidate := range t.Tiers {
+		if totalScore < candidate.MinScore {
+			break
+		}
+		tier = candidate
+	}
+	if tier.Name == "" && len(t.Tiers) > 0 {
+		tier = t.Tiers[0]
+	}
+	return tier
+}
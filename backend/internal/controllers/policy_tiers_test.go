@@ -0,0 +1,69 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestPolicyFor(t *testing.T) {
+	table := controllers.DefaultTierTable
+	cases := map[int]string{
+		0:  "Basic Cyber Insurance",
+		19: "Basic Cyber Insurance",
+		20: "Standard Cyber Insurance",
+		49: "Standard Cyber Insurance",
+		50: "Premium Cyber Insurance",
+		99: "Premium Cyber Insurance",
+	}
+	for score, want := range cases {
+		if got := controllers.PolicyFor(table, score); got != want {
+			t.Errorf("score %d: expected %q, got %q", score, want, got)
+		}
+	}
+}
+
+func TestValidateTierTable(t *testing.T) {
+	cases := map[string]models.TierTable{
+		"empty":          {Version: 1},
+		"nonzero start":  {Version: 1, Tiers: []models.PolicyTier{{Name: "A", MinScore: 5}}},
+		"missing name":   {Version: 1, Tiers: []models.PolicyTier{{Name: "A"}, {MinScore: 10}}},
+		"duplicate name": {Version: 1, Tiers: []models.PolicyTier{{Name: "A"}, {Name: "A", MinScore: 10}}},
+		"not increasing": {Version: 1, Tiers: []models.PolicyTier{{Name: "A"}, {Name: "B", MinScore: 10}, {Name: "C", MinScore: 10}}},
+	}
+	for name, table := range cases {
+		if err := controllers.ValidateTierTable(table); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+
+	if err := controllers.ValidateTierTable(controllers.DefaultTierTable); err != nil {
+		t.Errorf("default table should be valid: %v", err)
+	}
+}
+
+func TestSetTierTable(t *testing.T) {
+	defer controllers.SetTierTable(controllers.DefaultTierTable)
+
+	table := models.TierTable{Version: 2, Tiers: []models.PolicyTier{
+		{Name: "Basic", MinScore: 0},
+		{Name: "Advanced", MinScore: 10},
+	}}
+	if err := controllers.SetTierTable(table); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	questions := []models.Question{{ID: 1, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}}}
+	res := controllers.EvaluateAnswers(map[int]interface{}{1: "Yes"}, questions)
+	if res.Policy != "Advanced" {
+		t.Fatalf("expected the loaded table to be used, got %q", res.Policy)
+	}
+
+	if err := controllers.SetTierTable(models.TierTable{Version: 3}); err == nil {
+		t.Fatal("expected an invalid table to be rejected")
+	}
+	if controllers.CurrentTierTable().Version != 2 {
+		t.Fatal("an invalid table must not replace the current one")
+	}
+}
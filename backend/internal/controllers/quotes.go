@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"cyber-go/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Quote statuses. Referred quotes need an underwriter before binding.
+const (
+	QuoteStatusQuoted   = "quoted"
+	QuoteStatusReferred = "referred"
+)
+
+// ErrDeclined is returned when the underwriting decision rules out a quote.
+var ErrDeclined = errors.New("application declined by underwriting rules")
+
+// ErrUnknownTier is returned when a result recorded no coverage limit and
+// its tier is no longer in the tier table; rescoring records one.
+var ErrUnknownTier = errors.New("policy tier of the result no longer exists")
+
+// DefaultRatingTable is used until a table is loaded from the database.
+var DefaultRatingTable = models.RatingTable{
+	Version:  0,
+	Currency: "USD",
+	RevenueBands: map[string]models.RevenueBandRate{
+		"<1M":      {RatePerMillion: 1200, Deductible: 2500},
+		"1M-10M":   {RatePerMillion: 2500, Deductible: 5000},
+		"10M-50M":  {RatePerMillion: 5000, Deductible: 10000},
+		"50M-250M": {RatePerMillion: 9000, Deductible: 25000},
+		">250M":    {RatePerMillion: 15000, Deductible: 50000},
+	},
+	IndustryMultipliers: map[string]float64{
+		"healthcare":    1.5,
+		"finance":       1.4,
+		"education":     1.3,
+		"retail":        1.2,
+		"technology":    1.15,
+		"manufacturing": 1.1,
+		"other":         1.0,
+	},
+	WorstScoreMultiplier: 1.3,
+	BestScoreMultiplier:  0.8,
+	MinimumPremium:       500,
+	ValidFor:             30 * 24 * time.Hour,
+}
+
+var ratingTable = struct {
+	sync.RWMutex
+	data models.RatingTable
+}{data: DefaultRatingTable}
+
+// ValidateRatingTable checks that a rating table can price every profile:
+// it needs a currency, priced revenue bands, an "other" industry and
+// positive multipliers and validity.
+func ValidateRatingTable(t models.RatingTable) error {
+	if strings.TrimSpace(t.Currency) == "" {
+		return errors.New("rating table has no currency")
+	}
+	if len(t.RevenueBands) == 0 {
+		return errors.New("rating table has no revenue bands")
+	}
+	for name, band := range t.RevenueBands {
+		if band.RatePerMillion <= 0 {
+			return fmt.Errorf("revenue band %q must have a positive rate", name)
+		}
+		if band.Deductible < 0 {
+			return fmt.Errorf("revenue band %q has a negative deductible", name)
+		}
+	}
+	if _, ok := t.IndustryMultipliers["other"]; !ok {
+		return errors.New(`rating table has no "other" industry multiplier`)
+	}
+	for industry, m := range t.IndustryMultipliers {
+		if m <= 0 {
+			return fmt.Errorf("industry %q must have a positive multiplier", industry)
+		}
+	}
+	if t.WorstScoreMultiplier <= 0 || t.BestScoreMultiplier <= 0 {
+		return errors.New("score multipliers must be positive")
+	}
+	if t.MinimumPremium < 0 {
+		return errors.New("minimum premium must not be negative")
+	}
+	if t.ValidFor <= 0 {
+		return errors.New("quotes must be valid for a positive duration")
+	}
+	return nil
+}
+
+// SetRatingTable validates t and makes it the table quotes are priced with.
+func SetRatingTable(t models.RatingTable) error {
+	if err := ValidateRatingTable(t); err != nil {
+		return err
+	}
+	ratingTable.Lock()
+	ratingTable.data = t
+	ratingTable.Unlock()
+	return nil
+}
+
+// CurrentRatingTable returns the table quotes are priced with.
+func CurrentRatingTable() models.RatingTable {
+	ratingTable.RLock()
+	defer ratingTable.RUnlock()
+	return ratingTable.data
+}
+
+// ValidateProfile checks that the profile can be priced with the table.
+func ValidateProfile(t models.RatingTable, p models.OrgProfile) error {
+	if _, ok := t.RevenueBands[p.RevenueBand]; !ok {
+		return fmt.Errorf("unknown revenue band %q", p.RevenueBand)
+	}
+	if p.RequestedLimit <= 0 {
+		return errors.New("requested limit must be positive")
+	}
+	return nil
+}
+
+// PriceQuote turns a scored submission and an organization profile into a
+// quote. The limit is capped by the coverage limit the result was scored
+// with, when one is set. Results that recorded none are capped by their
+// tier in tiers, and fail with ErrUnknownTier if it is gone.
+func PriceQuote(t models.RatingTable, tiers models.TierTable, res models.Result, p models.OrgProfile, now time.Time) (models.Quote, error) {
+	if res.Decision.Outcome == OutcomeDecline {
+		return models.Quote{}, ErrDeclined
+	}
+	if err := ValidateProfile(t, p); err != nil {
+		return models.Quote{}, err
+	}
+	band := t.RevenueBands[p.RevenueBand]
+
+	coverage, err := coverageLimit(tiers, res)
+	if err != nil {
+		return models.Quote{}, err
+	}
+	limit := p.RequestedLimit
+	if coverage > 0 && limit > coverage {
+		limit = coverage
+	}
+
+	industry, ok := t.IndustryMultipliers[p.Industry]
+	if !ok {
+		industry = t.IndustryMultipliers["other"]
+	}
+	maxPoints := 0
+	for _, ps := range res.Paradigms {
+		maxPoints += ps.MaxPoints
+	}
+	share := percentage(res.TotalScore, maxPoints) / 100
+	security := t.WorstScoreMultiplier + (t.BestScoreMultiplier-t.WorstScoreMultiplier)*share
+
+	base := band.RatePerMillion * float64(limit) / 1e6
+	afterIndustry := base * industry
+	premium := afterIndustry * security
+	breakdown := []models.PremiumLine{
+		{Name: "base rate (" + p.RevenueBand + ")", Amount: roundCents(base)},
+		{Name: "industry", Factor: industry, Amount: roundCents(afterIndustry - base)},
+		{Name: "security score", Factor: roundFactor(security), Amount: roundCents(premium - afterIndustry)},
+	}
+	if premium < t.MinimumPremium {
+		breakdown = append(breakdown, models.PremiumLine{Name: "minimum premium", Amount: roundCents(t.MinimumPremium - premium)})
+		premium = t.MinimumPremium
+	}
+
+	status := QuoteStatusQuoted
+	if res.Decision.Outcome == OutcomeRefer {
+		status = QuoteStatusReferred
+	}
+
+	return models.Quote{
+		ID:           uuid.New().String(),
+		SubmissionID: res.SubmissionID,
+		Policy:       res.Policy,
+		Status:       status,
+		Profile:      p,
+		Limit:        limit,
+		Deductible:   band.Deductible,
+		Premium:      roundCents(premium),
+		Currency:     t.Currency,
+		Breakdown:    breakdown,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(t.ValidFor),
+	}, nil
+}
+
+func roundCents(v float64) float64 { return math.Round(v*100) / 100 }
+
+func roundFactor(v float64) float64 { return math.Round(v*1000) / 1000 }
+
+// coverageLimit returns the coverage limit a result is priced with.
+func coverageLimit(tiers models.TierTable, res models.Result) (int64, error) {
+	if res.CoverageLimit != nil {
+		return *res.CoverageLimit, nil
+	}
+	for _, tier := range tiers.Tiers {
+		if tier.Name == res.Policy {
+			return tier.CoverageLimit, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %q", ErrUnknownTier, res.Policy)
+}
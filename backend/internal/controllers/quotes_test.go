@@ -0,0 +1,142 @@
+package controllers_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func scoredResult(points, max int, outcome string) models.Result {
+	return models.Result{
+		SubmissionID: "sub-1",
+		TotalScore:   points,
+		Policy:       "Standard Cyber Insurance",
+		Decision:     models.Decision{Outcome: outcome},
+		Paradigms:    []models.ParadigmScore{{Paradigm: "Threat", Points: points, MaxPoints: max}},
+	}
+}
+
+func TestPriceQuote(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	profile := models.OrgProfile{Industry: "healthcare", RevenueBand: "1M-10M", RequestedLimit: 2_000_000}
+
+	q, err := controllers.PriceQuote(controllers.DefaultRatingTable, controllers.DefaultTierTable,
+		scoredResult(50, 100, controllers.OutcomeAccept), profile, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 2,500 per million * 2M * 1.5 healthcare * 1.05 for a 50% score
+	if q.Premium != 7875 {
+		t.Errorf("expected premium 7875, got %v", q.Premium)
+	}
+	if q.Deductible != 5000 || q.Limit != 2_000_000 || q.Status != controllers.QuoteStatusQuoted {
+		t.Errorf("unexpected quote terms: %+v", q)
+	}
+	if !q.ExpiresAt.Equal(now.Add(30 * 24 * time.Hour)) {
+		t.Errorf("unexpected expiry %v", q.ExpiresAt)
+	}
+	if q.SubmissionID != "sub-1" || len(q.Breakdown) != 3 {
+		t.Errorf("unexpected quote metadata: %+v", q)
+	}
+
+	sum := 0.0
+	for _, line := range q.Breakdown {
+		sum += line.Amount
+	}
+	if sum != q.Premium {
+		t.Errorf("breakdown %v does not add up to premium %v", sum, q.Premium)
+	}
+}
+
+func TestPriceQuoteCapsLimitAndHonorsDecision(t *testing.T) {
+	tiers := models.TierTable{Version: 1, Tiers: []models.PolicyTier{
+		{Name: "Standard Cyber Insurance", MinScore: 0, CoverageLimit: 1_000_000},
+	}}
+	profile := models.OrgProfile{Industry: "retail", RevenueBand: "<1M", RequestedLimit: 5_000_000}
+
+	q, err := controllers.PriceQuote(controllers.DefaultRatingTable, tiers,
+		scoredResult(10, 10, controllers.OutcomeRefer), profile, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Limit != 1_000_000 {
+		t.Errorf("expected the limit to be capped at the tier coverage, got %d", q.Limit)
+	}
+	if q.Status != controllers.QuoteStatusReferred {
+		t.Errorf("expected a referred quote, got %q", q.Status)
+	}
+
+	_, err = controllers.PriceQuote(controllers.DefaultRatingTable, tiers,
+		scoredResult(10, 10, controllers.OutcomeDecline), profile, time.Now())
+	if !errors.Is(err, controllers.ErrDeclined) {
+		t.Errorf("expected ErrDeclined, got %v", err)
+	}
+
+	profile.RevenueBand = "huge"
+	_, err = controllers.PriceQuote(controllers.DefaultRatingTable, tiers,
+		scoredResult(10, 10, controllers.OutcomeAccept), profile, time.Now())
+	if err == nil {
+		t.Error("expected an unknown revenue band to be rejected")
+	}
+}
+
+func TestPriceQuoteUsesTheLimitScoredWith(t *testing.T) {
+	// The tier has been renamed and re-limited since the result was scored.
+	tiers := models.TierTable{Version: 2, Tiers: []models.PolicyTier{
+		{Name: "Standard", MinScore: 0, CoverageLimit: 500_000},
+	}}
+	profile := models.OrgProfile{Industry: "retail", RevenueBand: "<1M", RequestedLimit: 5_000_000}
+
+	res := scoredResult(10, 10, controllers.OutcomeAccept)
+	recorded := int64(1_000_000)
+	res.CoverageLimit = &recorded
+	q, err := controllers.PriceQuote(controllers.DefaultRatingTable, tiers, res, profile, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Limit != 1_000_000 {
+		t.Errorf("expected the limit recorded with the result, got %d", q.Limit)
+	}
+
+	_, err = controllers.PriceQuote(controllers.DefaultRatingTable, tiers,
+		scoredResult(10, 10, controllers.OutcomeAccept), profile, time.Now())
+	if !errors.Is(err, controllers.ErrUnknownTier) {
+		t.Errorf("expected ErrUnknownTier for a result whose tier is gone, got %v", err)
+	}
+}
+
+func TestEvaluateAnswersRecordsCoverageLimit(t *testing.T) {
+	tiers := models.TierTable{Version: 1, Tiers: []models.PolicyTier{
+		{Name: "Basic", MinScore: 0, CoverageLimit: 250_000},
+	}}
+	res := controllers.EvaluateAnswersWith(map[int]interface{}{1: "Yes"}, underwritingCatalog(), tiers)
+	if res.Policy != "Basic" || res.CoverageLimit == nil || *res.CoverageLimit != 250_000 {
+		t.Errorf("expected the tier's coverage limit to be recorded, got %q %v", res.Policy, res.CoverageLimit)
+	}
+}
+
+func TestValidateRatingTable(t *testing.T) {
+	if err := controllers.ValidateRatingTable(controllers.DefaultRatingTable); err != nil {
+		t.Fatalf("expected the default rating table to be valid, got %v", err)
+	}
+
+	broken := map[string]func(*models.RatingTable){
+		"no currency":       func(r *models.RatingTable) { r.Currency = "" },
+		"no bands":          func(r *models.RatingTable) { r.RevenueBands = nil },
+		"free band":         func(r *models.RatingTable) { r.RevenueBands = map[string]models.RevenueBandRate{"<1M": {}} },
+		"no other industry": func(r *models.RatingTable) { r.IndustryMultipliers = map[string]float64{"retail": 1.2} },
+		"zero multiplier":   func(r *models.RatingTable) { r.BestScoreMultiplier = 0 },
+		"never valid":       func(r *models.RatingTable) { r.ValidFor = 0 },
+	}
+	for name, breakIt := range broken {
+		table := controllers.DefaultRatingTable
+		breakIt(&table)
+		if err := controllers.ValidateRatingTable(table); err == nil {
+			t.Errorf("%s: expected a validation error", name)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"cyber-go/internal/models"
+)
+
+// Rescore replays submissions through the scoring engine with a candidate
+// questionnaire and tier table and reports every applicant whose score,
+// tier or decision changes.
+func Rescore(subs []models.Submission, questions []models.Question, tiers models.TierTable) models.RescoreReport {
+	report := models.RescoreReport{
+		TierVersion: tiers.Version,
+		Applicants:  len(subs),
+		Affected:    []models.RescoreChange{},
+	}
+
+	before := make([]int, 0, len(subs))
+	after := make([]int, 0, len(subs))
+	for _, sub := range subs {
+		res := EvaluateAnswersWith(sub.Answers, questions, tiers)
+		before = append(before, sub.Result.TotalScore)
+		after = append(after, res.TotalScore)
+
+		if res.Policy != sub.Result.Policy {
+			report.TierChanges++
+		}
+		if res.TotalScore == sub.Result.TotalScore && res.Policy == sub.Result.Policy &&
+			res.Decision.Outcome == sub.Result.Decision.Outcome {
+			continue
+		}
+		report.Affected = append(report.Affected, models.RescoreChange{
+			UserID:       sub.UserID,
+			SubmissionID: sub.ID,
+			OldScore:     sub.Result.TotalScore,
+			NewScore:     res.TotalScore,
+			OldPolicy:    sub.Result.Policy,
+			NewPolicy:    res.Policy,
+			OldDecision:  sub.Result.Decision.Outcome,
+			NewDecision:  res.Decision.Outcome,
+		})
+	}
+
+	report.Before = distribution(before)
+	report.After = distribution(after)
+	if len(subs) > 0 {
+		shift := 0
+		for i := range before {
+			shift += after[i] - before[i]
+		}
+		report.MeanShift = math.Round(float64(shift)*100/float64(len(subs))) / 100
+	}
+	return report
+}
+
+func distribution(scores []int) models.ScoreDistribution {
+	if len(scores) == 0 {
+		return models.ScoreDistribution{}
+	}
+	sorted := append([]int(nil), scores...)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, s := range sorted {
+		sum += s
+	}
+	mid := len(sorted) / 2
+	median := float64(sorted[mid])
+	if len(sorted)%2 == 0 {
+		median = float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+	return models.ScoreDistribution{
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   math.Round(float64(sum)*100/float64(len(sorted))) / 100,
+		Median: median,
+	}
+}
+
+// WriteRescoreCSV writes the affected applicants of a report as CSV.
+func WriteRescoreCSV(w io.Writer, report models.RescoreReport) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"user_id", "submission_id", "old_score", "new_score", "old_policy", "new_policy", "old_decision", "new_decision"})
+	for _, c := range report.Affected {
+		cw.Write([]string{
+			c.UserID, c.SubmissionID,
+			strconv.Itoa(c.OldScore), strconv.Itoa(c.NewScore),
+			c.OldPolicy, c.NewPolicy,
+			c.OldDecision, c.NewDecision,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
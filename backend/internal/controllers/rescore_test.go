@@ -0,0 +1,60 @@
+package controllers_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestRescore(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Selector: "radio", Weight: 20, Options: []string{"Yes", "No"}},
+		{ID: 2, Selector: "radio", Weight: 30, Options: []string{"Yes", "No"}},
+	}
+	stored := func(id, user string, answers map[int]interface{}) models.Submission {
+		return models.Submission{ID: id, UserID: user, Answers: answers,
+			Result: controllers.EvaluateAnswers(answers, questions)}
+	}
+	subs := []models.Submission{
+		stored("s1", "alice", map[int]interface{}{1: "Yes", 2: "No"}),
+		stored("s2", "bob", map[int]interface{}{1: "Yes", 2: "Yes"}),
+		stored("s3", "carol", map[int]interface{}{1: "No", 2: "No"}),
+	}
+
+	// Raising the Standard threshold to 25 drops alice (20 points) to Basic.
+	candidate := models.TierTable{Version: 7, Tiers: []models.PolicyTier{
+		{Name: "Basic Cyber Insurance", MinScore: 0},
+		{Name: "Standard Cyber Insurance", MinScore: 25},
+		{Name: "Premium Cyber Insurance", MinScore: 50},
+	}}
+	report := controllers.Rescore(subs, questions, candidate)
+	if report.Applicants != 3 || report.TierChanges != 1 || report.TierVersion != 7 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if len(report.Affected) != 1 || report.Affected[0].UserID != "alice" || report.Affected[0].NewPolicy != "Basic Cyber Insurance" {
+		t.Errorf("unexpected affected list: %+v", report.Affected)
+	}
+	if report.MeanShift != 0 {
+		t.Errorf("the scores did not change, got a shift of %v", report.MeanShift)
+	}
+
+	// Doubling a weight shifts the distribution.
+	heavier := append([]models.Question(nil), questions...)
+	heavier[0].Weight = 40
+	report = controllers.Rescore(subs, heavier, controllers.DefaultTierTable)
+	if report.Before.Median != 20 || report.After.Median != 40 || report.MeanShift != 13.33 {
+		t.Errorf("unexpected distribution shift: before %+v after %+v shift %v", report.Before, report.After, report.MeanShift)
+	}
+
+	var buf bytes.Buffer
+	if err := controllers.WriteRescoreCSV(&buf, report); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1+len(report.Affected) || !strings.HasPrefix(lines[0], "user_id,") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
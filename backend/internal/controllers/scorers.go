@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	"cyber-go/internal/models"
+)
+
+// Scorer scores a single answer for one selector type. Validate is always
+// called before Score, so Score may assume the answer has the right shape.
+// MaxScore reports the most points any answer to q can earn.
+type Scorer interface {
+	Validate(q models.Question, ans interface{}) error
+	Score(q models.Question, ans interface{}) int
+	MaxScore(q models.Question) int
+}
+
+var scorers = struct {
+	sync.RWMutex
+	data map[string]Scorer
+}{data: make(map[string]Scorer)}
+
+func init() {
+	RegisterScorer("radio", radioScorer{})
+	RegisterScorer("checkbox", checkboxScorer{})
+	RegisterScorer("dropdown", dropdownScorer{})
+	RegisterScorer("number", numberScorer{})
+	RegisterScorer("date", dateScorer{})
+}
+
+// RegisterScorer adds or replaces the scorer used for a selector name.
+func RegisterScorer(selector string, s Scorer) {
+	scorers.Lock()
+	scorers.data[selector] = s
+	scorers.Unlock()
+}
+
+// ScorerFor returns the scorer registered for a selector name.
+func ScorerFor(selector string) (Scorer, bool) {
+	scorers.RLock()
+	s, ok := scorers.data[selector]
+	scorers.RUnlock()
+	return s, ok
+}
+
+// --- Built-in scorers ---
+
+// radioScorer awards the full weight for a "Yes" answer, or the chosen
+// option's score when the question defines choices.
+type radioScorer struct{}
+
+func (radioScorer) Validate(q models.Question, ans interface{}) error {
+	s, ok := ans.(string)
+	if !ok {
+		return fmt.Errorf("%w: expected a string, got %T", ErrWrongType, ans)
+	}
+	return checkOption(q, s)
+}
+
+func (radioScorer) Score(q models.Question, ans interface{}) int {
+	if len(q.Choices) > 0 {
+		return singleChoiceScore(q, ans.(string))
+	}
+	if ans.(string) == "Yes" {
+		return q.Weight
+	}
+	return 0
+}
+
+func (radioScorer) MaxScore(q models.Question) int {
+	if len(q.Choices) > 0 {
+		return singleChoiceMax(q)
+	}
+	return q.Weight
+}
+
+// checkboxScorer awards the weight proportionally to the number of
+// selections, or the sum of the selected options' scores when the question
+// defines choices.
+type checkboxScorer struct{}
+
+func (checkboxScorer) Validate(q models.Question, ans interface{}) error {
+	selected, err := toStringSlice(ans)
+	if err != nil {
+		return err
+	}
+	if len(q.Options) == 0 {
+		return fmt.Errorf("question %d has no options", q.ID)
+	}
+	for _, s := range selected {
+		if err := checkOption(q, s); err != nil {
+			return err
+		}
+	}
+	return checkSelections(q, selected)
+}
+
+func (checkboxScorer) Score(q models.Question, ans interface{}) int {
+	selected, _ := toStringSlice(ans)
+	if len(q.Choices) == 0 {
+		return q.Weight * len(selected) / len(q.Options)
+	}
+
+	points := 0
+	for _, s := range selected {
+		if c, ok := findChoice(q, s); ok {
+			points += choicePoints(c)
+		}
+	}
+	if points < 0 {
+		return 0
+	}
+	if max := multiChoiceMax(q); points > max {
+		return max
+	}
+	return points
+}
+
+func (checkboxScorer) MaxScore(q models.Question) int {
+	if len(q.Choices) > 0 {
+		return multiChoiceMax(q)
+	}
+	return q.Weight
+}
+
+// dropdownScorer awards the weight proportionally to the option position,
+// or the chosen option's score when the question defines choices.
+type dropdownScorer struct{}
+
+func (dropdownScorer) Validate(q models.Question, ans interface{}) error {
+	s, ok := ans.(string)
+	if !ok {
+		return fmt.Errorf("%w: expected a string, got %T", ErrWrongType, ans)
+	}
+	if len(q.Options) == 0 {
+		return fmt.Errorf("question %d has no options", q.ID)
+	}
+	return checkOption(q, s)
+}
+
+func (dropdownScorer) Score(q models.Question, ans interface{}) int {
+	if len(q.Choices) > 0 {
+		return singleChoiceScore(q, ans.(string))
+	}
+	optionIndex := indexOf(ans.(string), q.Options)
+	return q.Weight * (optionIndex + 1) / len(q.Options)
+}
+
+func (dropdownScorer) MaxScore(q models.Question) int {
+	if len(q.Choices) > 0 {
+		return singleChoiceMax(q)
+	}
+	return q.Weight
+}
+
+// singleChoiceScore scores a radio or dropdown answer by its option; a
+// negative option cannot take the question below zero.
+func singleChoiceScore(q models.Question, ans string) int {
+	c, _ := findChoice(q, ans)
+	if p := choicePoints(c); p > 0 {
+		return p
+	}
+	return 0
+}
+
+// checkOption rejects answers that are not one of the question's options.
+// Questions without options accept any value.
+func checkOption(q models.Question, ans string) error {
+	if len(q.Options) == 0 {
+		return nil
+	}
+	for _, opt := range q.Options {
+		if opt == ans {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q is not one of %v", ErrInvalidOption, ans, q.Options)
+}
+
+// toStringSlice accepts both []string and the []interface{} produced by
+// encoding/json, rejecting any non-string element.
+func toStringSlice(ans interface{}) ([]string, error) {
+	switch v := ans.(type) {
+	case []string:
+		return v, nil
+	case []interface{}:
+		out := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("%w: expected a list of strings, got %T at index %d", ErrWrongType, item, i)
+			}
+			out[i] = s
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("%w: expected a list of strings, got %T", ErrWrongType, ans)
+	}
+}
@@ -0,0 +1,127 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+type fixedScorer struct{ points int }
+
+func (fixedScorer) Validate(q models.Question, ans interface{}) error { return nil }
+
+func (s fixedScorer) Score(q models.Question, ans interface{}) int { return s.points }
+
+func (s fixedScorer) MaxScore(q models.Question) int { return s.points }
+
+func TestEvaluateAnswersBuiltInScorers(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+		{ID: 2, Selector: "checkbox", Weight: 9, Options: []string{"AWS", "GCP", "Azure"}},
+		{ID: 3, Selector: "dropdown", Weight: 8, Options: []string{"Low", "Medium", "High", "Max"}},
+	}
+	answers := map[int]interface{}{
+		1: "Yes",
+		2: []interface{}{"AWS", "GCP"},
+		3: "Medium",
+	}
+
+	res := controllers.EvaluateAnswers(answers, questions)
+	if res.TotalScore != 10+6+4 {
+		t.Fatalf("expected total 20, got %d", res.TotalScore)
+	}
+}
+
+func TestEvaluateAnswersIgnoresInvalidShapes(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+		{ID: 2, Selector: "checkbox", Weight: 10, Options: []string{"AWS", "GCP"}},
+		{ID: 3, Selector: "unknown", Weight: 10},
+	}
+	answers := map[int]interface{}{
+		1: 42.0,
+		2: []interface{}{"AWS", 7.0},
+		3: "Yes",
+	}
+
+	res := controllers.EvaluateAnswers(answers, questions)
+	if res.TotalScore != 0 {
+		t.Fatalf("expected invalid answers to score 0, got %d", res.TotalScore)
+	}
+}
+
+func TestRegisterScorer(t *testing.T) {
+	if _, ok := controllers.ScorerFor("fixed"); ok {
+		t.Fatal("expected no scorer for the fixed selector yet")
+	}
+	controllers.RegisterScorer("fixed", fixedScorer{points: 7})
+	t.Cleanup(func() { controllers.UnregisterScorer("fixed") })
+
+	questions := []models.Question{{ID: 1, Selector: "fixed", Weight: 10}}
+	res := controllers.EvaluateAnswers(map[int]interface{}{1: "anything"}, questions)
+	if res.TotalScore != 7 {
+		t.Fatalf("expected custom scorer to award 7, got %d", res.TotalScore)
+	}
+}
+
+func TestEvaluateAnswersParadigmBreakdown(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Paradigm: "Threat", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+		{ID: 2, Paradigm: "Vulnerability", Selector: "radio", Weight: 20, Options: []string{"Yes", "No"}},
+		{ID: 3, Paradigm: "Threat", Selector: "checkbox", Weight: 10, Options: []string{"AWS", "GCP"}},
+	}
+	answers := map[int]interface{}{
+		1: "Yes",
+		2: "No",
+		3: []string{"AWS"},
+	}
+
+	res := controllers.EvaluateAnswers(answers, questions)
+	want := []models.ParadigmScore{
+		{Paradigm: "Threat", Points: 15, MaxPoints: 20, Percentage: 75},
+		{Paradigm: "Vulnerability", Points: 0, MaxPoints: 20, Percentage: 0},
+	}
+	if len(res.Paradigms) != len(want) {
+		t.Fatalf("expected %d paradigms, got %+v", len(want), res.Paradigms)
+	}
+	for i, w := range want {
+		if res.Paradigms[i] != w {
+			t.Errorf("paradigm %d: expected %+v, got %+v", i, w, res.Paradigms[i])
+		}
+	}
+}
+
+func TestEvaluateAnswersTrace(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Paradigm: "Threat", Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+		{ID: 2, Paradigm: "Threat", Selector: "checkbox", Weight: 10, Options: []string{"AWS", "GCP"}},
+		{ID: 3, Paradigm: "Vulnerability", Selector: "dropdown", Weight: 10, Options: []string{"Low", "High"}},
+	}
+	answers := map[int]interface{}{1: "Yes", 2: []string{"AWS"}}
+
+	res := controllers.EvaluateAnswers(answers, questions)
+	if len(res.Trace) != 3 {
+		t.Fatalf("expected a trace entry per question, got %+v", res.Trace)
+	}
+
+	sum := 0
+	for i, entry := range res.Trace {
+		if entry.QuestionID != questions[i].ID {
+			t.Errorf("trace entry %d: expected question %d, got %d", i, questions[i].ID, entry.QuestionID)
+		}
+		if entry.Rule != questions[i].Selector || entry.Weight != 10 || entry.MaxPoints != 10 {
+			t.Errorf("trace entry %d: unexpected rule metadata %+v", i, entry)
+		}
+		sum += entry.Points
+	}
+	if sum != res.TotalScore {
+		t.Errorf("trace points %d do not add up to total %d", sum, res.TotalScore)
+	}
+	if res.Trace[1].Points != 5 {
+		t.Errorf("expected checkbox to award 5 points, got %d", res.Trace[1].Points)
+	}
+	if res.Trace[2].Note != "not answered" {
+		t.Errorf("expected unanswered question to be noted, got %q", res.Trace[2].Note)
+	}
+}
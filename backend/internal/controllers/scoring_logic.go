@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"math"
+
 	"cyber-go/internal/models"
 )
 
@@ -14,41 +16,86 @@ func indexOf(val string, arr []string) int {
 	return 0
 }
 
-func determinePolicy(totalScore int) string {
-	switch {
-	case totalScore < 20:
-		return "Basic Cyber Insurance"
-	case totalScore < 50:
-		return "Standard Cyber Insurance"
-	default:
-		return "Premium Cyber Insurance"
-	}
+// EvaluateAnswers scores each answered question with the scorer registered
+// for its selector, breaks the total down per paradigm and records a trace
+// entry per question in catalog order. Questions hidden by their conditions
+// count towards neither the score nor the maximums. Answers for unknown
+// selectors or with the wrong shape score zero. The underwriting rules
+// decide separately whether the application is accepted, referred or declined.
+func EvaluateAnswers(answers map[int]interface{}, questions []models.Question) models.Result {
+	return EvaluateAnswersWith(answers, questions, CurrentTierTable())
 }
 
-func EvaluateAnswers(answers map[int]interface{}, questions []models.Question) (int, string) {
+// EvaluateAnswersWith is EvaluateAnswers with an explicit tier table, used to
+// replay submissions against candidate tiers.
+func EvaluateAnswersWith(answers map[int]interface{}, questions []models.Question, tiers models.TierTable) models.Result {
 	totalScore := 0
+	var breakdown []models.ParadigmScore
+	var trace []models.QuestionTrace
+	byParadigm := make(map[string]int)
+	visible := visibility(questions, answers)
 
 	for _, q := range questions {
-		ans, ok := answers[q.ID]
+		ans, answered := answers[q.ID]
+		entry := models.QuestionTrace{QuestionID: q.ID, Paradigm: q.Paradigm, Answer: ans, Rule: q.Selector, Weight: q.Weight}
+
+		if !visible[q.ID] {
+			entry.Note = "hidden by condition"
+			trace = append(trace, entry)
+			continue
+		}
+
+		scorer, ok := ScorerFor(q.Selector)
 		if !ok {
+			entry.Note = "unknown selector"
+			trace = append(trace, entry)
+			continue
+		}
+		entry.MaxPoints = scorer.MaxScore(q)
+
+		idx, seen := byParadigm[q.Paradigm]
+		if !seen {
+			idx = len(breakdown)
+			byParadigm[q.Paradigm] = idx
+			breakdown = append(breakdown, models.ParadigmScore{Paradigm: q.Paradigm})
+		}
+		breakdown[idx].MaxPoints += entry.MaxPoints
+
+		if !answered {
+			entry.Note = "not answered"
+			trace = append(trace, entry)
 			continue
 		}
-		score := 0
-		switch q.Selector {
-		case "radio":
-			if ans.(string) == "Yes" {
-				score = q.Weight
-			}
-		case "checkbox":
-			selected := ans.([]string)
-			score = q.Weight * len(selected) / len(q.Options)
-		case "dropdown":
-			optionIndex := indexOf(ans.(string), q.Options)
-			score = q.Weight * (optionIndex + 1) / len(q.Options)
+		if err := scorer.Validate(q, ans); err != nil {
+			entry.Note = "invalid answer: " + err.Error()
+			trace = append(trace, entry)
+			continue
 		}
-		totalScore += score
+		entry.Points = scorer.Score(q, ans)
+		breakdown[idx].Points += entry.Points
+		totalScore += entry.Points
+		trace = append(trace, entry)
+	}
+
+	for i := range breakdown {
+		breakdown[i].Percentage = percentage(breakdown[i].Points, breakdown[i].MaxPoints)
 	}
 
-	policy := determinePolicy(totalScore)
-	return totalScore, policy
+	tier := tierFor(tiers, totalScore)
+	return models.Result{
+		TotalScore:    totalScore,
+		Policy:        tier.Name,
+		CoverageLimit: &tier.CoverageLimit,
+		Decision:      Decide(CurrentRules(), answers, questions),
+		Paradigms:     breakdown,
+		Trace:         trace,
+	}
+}
+
+// percentage normalizes points to 0-100, rounded to two decimals.
+func percentage(points, max int) float64 {
+	if max <= 0 {
+		return 0
+	}
+	return math.Round(float64(points)*10000/float64(max)) / 100
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"cyber-go/internal/handlers"
+	"cyber-go/internal/repositories"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 )
@@ -21,19 +22,24 @@ func TestSubmitHandler(t *testing.T) {
 	defer db.Close()
 
 	// Set the global DB connection in your handlers package
-	handlers.SetDB(db)
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
 
 	// Define the expected database operations and their results
 	// The handler will likely perform a SELECT to get question data
-	rows := sqlmock.NewRows([]string{"id", "weight", "selector", "options"}).
-		AddRow(1, 10, "radio", "Yes,No").
-		AddRow(2, 10, "checkbox", "AWS,GCP,Azure").
-		AddRow(3, 15, "radio", "Yes,No")
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+		AddRow(2, 102, "Question 2", "checkbox", "AWS,GCP,Azure", 0, 0, 10, false, nil, nil).
+		AddRow(3, 103, "Question 3", "radio", "Yes,No", 0, 0, 15, true, nil, nil)
 
-	mock.ExpectQuery("SELECT id, weight, selector, options FROM questions").WillReturnRows(rows)
+	// No questionnaire is published, so the live questions table is used.
+	// The questions are read and the submission stored in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").WillReturnRows(rows)
 
 	// The handler will also perform an INSERT to save the result
-	mock.ExpectExec("INSERT INTO results").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO submissions").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	// Create the HTTP payload
 	payload := map[string]interface{}{
@@ -52,7 +58,7 @@ func TestSubmitHandler(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Call the handler
-	handlers.SubmitHandler(w, req)
+	h.SubmitHandler(w, req)
 
 	// Check the response
 	res := w.Result()
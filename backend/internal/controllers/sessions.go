@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"cyber-go/internal/models"
+)
+
+// MergeAnswers applies changes to the saved answers of a draft. A null
+// answer clears the saved one.
+func MergeAnswers(saved, changes map[int]interface{}) map[int]interface{} {
+	merged := make(map[int]interface{}, len(saved)+len(changes))
+	for id, ans := range saved {
+		merged[id] = ans
+	}
+	for id, ans := range changes {
+		if ans == nil {
+			delete(merged, id)
+			continue
+		}
+		merged[id] = ans
+	}
+	return merged
+}
+
+// ValidateDraftAnswers checks the answers changed in a draft, in the
+// context of everything answered so far. Unlike ValidateAnswers it does not
+// require unanswered questions, and it only reports the changed answers.
+func ValidateDraftAnswers(changes, merged map[int]interface{}, questions []models.Question) []models.AnswerError {
+	var errs []models.AnswerError
+	for _, e := range ValidateAnswers(merged, questions) {
+		if _, changed := changes[e.QuestionID]; changed && e.Code != CodeMissingAnswer {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// SessionProgress counts, per paradigm in catalog order, the visible
+// questions and how many of them are answered. The session is complete
+// once no visible required question is left unanswered.
+func SessionProgress(questions []models.Question, answers map[int]interface{}) ([]models.ParadigmProgress, bool) {
+	progress := []models.ParadigmProgress{}
+	index := map[string]int{}
+	complete := true
+
+	for _, q := range VisibleQuestions(questions, answers) {
+		i, ok := index[q.Paradigm]
+		if !ok {
+			i = len(progress)
+			index[q.Paradigm] = i
+			progress = append(progress, models.ParadigmProgress{Paradigm: q.Paradigm})
+		}
+		p := &progress[i]
+		p.Total++
+		if ans, ok := answers[q.ID]; ok && ans != nil {
+			p.Answered++
+		} else if q.Required {
+			p.RequiredMissing++
+			complete = false
+		}
+	}
+	return progress, complete
+}
@@ -0,0 +1,58 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func sessionQuestions() []models.Question {
+	return []models.Question{
+		{ID: 1, Paradigm: "1", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10, Required: true},
+		{ID: 2, Paradigm: "1", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10, Required: true,
+			Conditions: []models.Condition{{QuestionID: 1, AnyOf: []string{"Yes"}}}},
+		{ID: 3, Paradigm: "2", Selector: "checkbox", Options: []string{"AWS", "GCP"}, Weight: 10},
+	}
+}
+
+func TestValidateDraftAnswers(t *testing.T) {
+	qs := sessionQuestions()
+	saved := map[int]interface{}{1: "Yes"}
+	changes := map[int]interface{}{3: []interface{}{"Oracle"}, 42: "Yes"}
+
+	errs := controllers.ValidateDraftAnswers(changes, controllers.MergeAnswers(saved, changes), qs)
+	if len(errs) != 2 || errs[0].QuestionID != 3 || errs[0].Code != controllers.CodeInvalidOption || errs[1].Code != controllers.CodeUnknownQuestion {
+		t.Errorf("expected errors for the changed answers only, got %+v", errs)
+	}
+
+	changes = map[int]interface{}{3: []interface{}{"AWS"}}
+	if errs := controllers.ValidateDraftAnswers(changes, controllers.MergeAnswers(saved, changes), qs); len(errs) != 0 {
+		t.Errorf("unanswered required questions must not fail a draft, got %+v", errs)
+	}
+}
+
+func TestMergeAnswersClearsNull(t *testing.T) {
+	merged := controllers.MergeAnswers(map[int]interface{}{1: "Yes", 2: "No"}, map[int]interface{}{2: nil, 3: "x"})
+	if _, ok := merged[2]; ok || merged[1] != "Yes" || merged[3] != "x" {
+		t.Errorf("unexpected merge: %+v", merged)
+	}
+}
+
+func TestSessionProgress(t *testing.T) {
+	qs := sessionQuestions()
+
+	progress, complete := controllers.SessionProgress(qs, map[int]interface{}{1: "Yes"})
+	if complete || len(progress) != 2 {
+		t.Fatalf("expected incomplete progress over two paradigms, got %+v", progress)
+	}
+	if p := progress[0]; p.Answered != 1 || p.Total != 2 || p.RequiredMissing != 1 {
+		t.Errorf("unexpected progress of paradigm 1: %+v", p)
+	}
+
+	// Answering No hides question 2, which completes the session
+	progress, complete = controllers.SessionProgress(qs, map[int]interface{}{1: "No"})
+	if !complete || progress[0].Total != 1 || progress[1].RequiredMissing != 0 {
+		t.Errorf("expected a complete session, got %+v", progress)
+	}
+}
@@ -0,0 +1,24 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cyber-go/internal/models"
+)
+
+// tenantID restricts tenant ids to what is safe in headers, tokens and URLs.
+var tenantID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ValidateTenant checks a tenant before it is created.
+func ValidateTenant(t models.Tenant) error {
+	if !tenantID.MatchString(t.ID) {
+		return fmt.Errorf("tenant id %q must be 1-63 lowercase letters, digits or dashes, starting with a letter or digit", t.ID)
+	}
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("tenant name is required")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestValidateTenant(t *testing.T) {
+	valid := []models.Tenant{
+		{ID: "acme", Name: "Acme Brokers"},
+		{ID: "carrier-2", Name: "Carrier 2"},
+	}
+	for _, tn := range valid {
+		if err := controllers.ValidateTenant(tn); err != nil {
+			t.Errorf("expected %+v to be valid, got %v", tn, err)
+		}
+	}
+
+	invalid := []models.Tenant{
+		{ID: "", Name: "Empty"},
+		{ID: "Acme", Name: "Upper case"},
+		{ID: "-acme", Name: "Leading dash"},
+		{ID: "acme brokers", Name: "Space"},
+		{ID: "acme", Name: "  "},
+	}
+	for _, tn := range invalid {
+		if err := controllers.ValidateTenant(tn); err == nil {
+			t.Errorf("expected %+v to be rejected", tn)
+		}
+	}
+}
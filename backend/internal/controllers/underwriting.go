@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"cyber-go/internal/models"
+)
+
+// Underwriting outcomes, from least to most severe.
+const (
+	OutcomeAccept  = "accept"
+	OutcomeRefer   = "refer"
+	OutcomeDecline = "decline"
+)
+
+var severity = map[string]int{OutcomeAccept: 0, OutcomeRefer: 1, OutcomeDecline: 2}
+
+var underwritingRules = struct {
+	sync.RWMutex
+	data []models.UnderwritingRule
+}{}
+
+// ValidateRules checks that every rule has a unique ID, a refer or decline
+// action and at least one condition.
+func ValidateRules(rules []models.UnderwritingRule) error {
+	ids := make(map[string]bool, len(rules))
+	for i, rule := range rules {
+		if rule.ID == "" {
+			return fmt.Errorf("rule %d has no id", i)
+		}
+		if ids[rule.ID] {
+			return fmt.Errorf("duplicate rule id %q", rule.ID)
+		}
+		ids[rule.ID] = true
+		if rule.Action != OutcomeRefer && rule.Action != OutcomeDecline {
+			return fmt.Errorf("rule %q: action must be %q or %q, got %q", rule.ID, OutcomeRefer, OutcomeDecline, rule.Action)
+		}
+		if len(rule.When) == 0 {
+			return fmt.Errorf("rule %q has no conditions", rule.ID)
+		}
+		for _, c := range rule.When {
+			if len(c.AnyOf) == 0 {
+				return fmt.Errorf("rule %q has a condition without values", rule.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateRulesAgainst checks that every condition of rules is on a question
+// of the catalog and, for questions with options, only names those options.
+// Decide skips conditions on questions it cannot see, so a rule on a
+// mistyped or retired question would otherwise never fire.
+func ValidateRulesAgainst(rules []models.UnderwritingRule, questions []models.Question) error {
+	byID := make(map[int]models.Question, len(questions))
+	for _, q := range questions {
+		byID[q.ID] = q
+	}
+	for _, rule := range rules {
+		for _, c := range rule.When {
+			q, ok := byID[c.QuestionID]
+			if !ok {
+				return fmt.Errorf("rule %q has a condition on unknown question %d", rule.ID, c.QuestionID)
+			}
+			if len(q.Options) == 0 {
+				continue
+			}
+			for _, value := range c.AnyOf {
+				if !slices.Contains(q.Options, value) {
+					return fmt.Errorf("rule %q: %q is not an option of question %d", rule.ID, value, q.ID)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SetRules validates rules and makes them the ones used for decisions.
+func SetRules(rules []models.UnderwritingRule) error {
+	if err := ValidateRules(rules); err != nil {
+		return err
+	}
+	underwritingRules.Lock()
+	underwritingRules.data = rules
+	underwritingRules.Unlock()
+	return nil
+}
+
+// CurrentRules returns the rules used for decisions.
+func CurrentRules() []models.UnderwritingRule {
+	underwritingRules.RLock()
+	defer underwritingRules.RUnlock()
+	return underwritingRules.data
+}
+
+// Decide evaluates the rules against the visible answers. The most severe
+// action among the rules that fire becomes the outcome.
+func Decide(rules []models.UnderwritingRule, answers map[int]interface{}, questions []models.Question) models.Decision {
+	visible := visibility(questions, answers)
+	decision := models.Decision{Outcome: OutcomeAccept, Reasons: []models.DecisionReason{}}
+
+	for _, rule := range rules {
+		fired := true
+		for _, c := range rule.When {
+			if !visible[c.QuestionID] || !conditionHolds(c, answers[c.QuestionID]) {
+				fired = false
+				break
+			}
+		}
+		if !fired {
+			continue
+		}
+		decision.Reasons = append(decision.Reasons, models.DecisionReason{RuleID: rule.ID, Action: rule.Action, Reason: rule.Reason})
+		if severity[rule.Action] > severity[decision.Outcome] {
+			decision.Outcome = rule.Action
+		}
+	}
+	return decision
+}
@@ -0,0 +1,96 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func underwritingCatalog() []models.Question {
+	return []models.Question{
+		{ID: 1, Selector: "radio", Weight: 50, Options: []string{"Yes", "No"}},
+		{ID: 2, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"},
+			Conditions: []models.Condition{{QuestionID: 1, AnyOf: []string{"Yes"}}}},
+		{ID: 3, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+	}
+}
+
+var underwritingRules = []models.UnderwritingRule{
+	{ID: "no-mfa", Action: controllers.OutcomeDecline, Reason: "No MFA on remote access",
+		When: []models.Condition{{QuestionID: 2, AnyOf: []string{"No"}}}},
+	{ID: "no-offline-backups", Action: controllers.OutcomeRefer, Reason: "No offline backups",
+		When: []models.Condition{{QuestionID: 3, AnyOf: []string{"No"}}}},
+}
+
+func TestDecide(t *testing.T) {
+	catalog := underwritingCatalog()
+	cases := []struct {
+		answers map[int]interface{}
+		outcome string
+		reasons int
+	}{
+		{map[int]interface{}{1: "Yes", 2: "Yes", 3: "Yes"}, controllers.OutcomeAccept, 0},
+		{map[int]interface{}{1: "Yes", 2: "Yes", 3: "No"}, controllers.OutcomeRefer, 1},
+		{map[int]interface{}{1: "Yes", 2: "No", 3: "No"}, controllers.OutcomeDecline, 2},
+		// Question 2 is hidden, so its answer cannot trigger a knockout.
+		{map[int]interface{}{1: "No", 2: "No", 3: "Yes"}, controllers.OutcomeAccept, 0},
+	}
+	for _, c := range cases {
+		d := controllers.Decide(underwritingRules, c.answers, catalog)
+		if d.Outcome != c.outcome || len(d.Reasons) != c.reasons {
+			t.Errorf("answers %v: expected %s with %d reasons, got %+v", c.answers, c.outcome, c.reasons, d)
+		}
+	}
+}
+
+func TestDecisionIsIndependentOfScore(t *testing.T) {
+	if err := controllers.SetRules(underwritingRules); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer controllers.SetRules(nil)
+
+	res := controllers.EvaluateAnswers(map[int]interface{}{1: "Yes", 2: "No", 3: "Yes"}, underwritingCatalog())
+	if res.Policy != "Premium Cyber Insurance" {
+		t.Fatalf("expected a premium score, got %q", res.Policy)
+	}
+	if res.Decision.Outcome != controllers.OutcomeDecline {
+		t.Errorf("expected the knockout to decline, got %+v", res.Decision)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	cases := map[string][]models.UnderwritingRule{
+		"missing id":     {{Action: "refer", When: []models.Condition{{QuestionID: 1, AnyOf: []string{"No"}}}}},
+		"unknown action": {{ID: "a", Action: "approve", When: []models.Condition{{QuestionID: 1, AnyOf: []string{"No"}}}}},
+		"no conditions":  {{ID: "a", Action: "refer"}},
+		"empty values":   {{ID: "a", Action: "refer", When: []models.Condition{{QuestionID: 1}}}},
+		"duplicate id": {
+			{ID: "a", Action: "refer", When: []models.Condition{{QuestionID: 1, AnyOf: []string{"No"}}}},
+			{ID: "a", Action: "decline", When: []models.Condition{{QuestionID: 2, AnyOf: []string{"No"}}}},
+		},
+	}
+	for name, rules := range cases {
+		if err := controllers.ValidateRules(rules); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestValidateRulesAgainstCatalog(t *testing.T) {
+	catalog := underwritingCatalog()
+	if err := controllers.ValidateRulesAgainst(underwritingRules, catalog); err != nil {
+		t.Errorf("expected the rules to match the catalog, got %v", err)
+	}
+
+	invalid := [][]models.Condition{
+		{{QuestionID: 42, AnyOf: []string{"No"}}},
+		{{QuestionID: 2, AnyOf: []string{"no"}}},
+	}
+	for _, when := range invalid {
+		rules := []models.UnderwritingRule{{ID: "bad", Action: controllers.OutcomeDecline, When: when}}
+		if err := controllers.ValidateRulesAgainst(rules, catalog); err == nil {
+			t.Errorf("expected a rule on %+v to be rejected", when)
+		}
+	}
+}
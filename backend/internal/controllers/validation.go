@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"cyber-go/internal/models"
+)
+
+// Codes reported in models.AnswerError.
+const (
+	CodeUnknownQuestion = "unknown_question"
+	CodeWrongType       = "wrong_type"
+	CodeInvalidOption   = "invalid_option"
+	CodeMissingAnswer   = "missing_answer"
+	CodeOutOfRange      = "out_of_range"
+	CodeUnknownSelector = "unknown_selector"
+)
+
+// Scorers wrap these so ValidateAnswers can tell the failure kinds apart.
+var (
+	ErrWrongType     = errors.New("wrong answer type")
+	ErrInvalidOption = errors.New("invalid option")
+	ErrOutOfRange    = errors.New("value out of range")
+)
+
+// ValidateAnswers checks every answer against the question catalog and
+// returns one error per offending question, ordered by question ID. Answers
+// to questions hidden by their conditions are ignored.
+func ValidateAnswers(answers map[int]interface{}, questions []models.Question) []models.AnswerError {
+	var errs []models.AnswerError
+	known := make(map[int]bool, len(questions))
+	visible := visibility(questions, answers)
+
+	for _, q := range questions {
+		known[q.ID] = true
+		if !visible[q.ID] {
+			continue
+		}
+
+		ans, ok := answers[q.ID]
+		if !ok || ans == nil {
+			if q.Required {
+				errs = append(errs, models.AnswerError{QuestionID: q.ID, Code: CodeMissingAnswer, Message: "an answer is required"})
+			}
+			continue
+		}
+
+		scorer, ok := ScorerFor(q.Selector)
+		if !ok {
+			errs = append(errs, models.AnswerError{QuestionID: q.ID, Code: CodeUnknownSelector,
+				Message: fmt.Sprintf("selector %q is not supported", q.Selector)})
+			continue
+		}
+		if err := scorer.Validate(q, ans); err != nil {
+			code := CodeWrongType
+			switch {
+			case errors.Is(err, ErrInvalidOption):
+				code = CodeInvalidOption
+			case errors.Is(err, ErrOutOfRange):
+				code = CodeOutOfRange
+			}
+			errs = append(errs, models.AnswerError{QuestionID: q.ID, Code: code, Message: err.Error()})
+		}
+	}
+
+	for id := range answers {
+		if !known[id] {
+			errs = append(errs, models.AnswerError{QuestionID: id, Code: CodeUnknownQuestion, Message: "question does not exist"})
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].QuestionID < errs[j].QuestionID })
+	return errs
+}
@@ -0,0 +1,56 @@
+package controllers_test
+
+import (
+	"testing"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+)
+
+func TestValidateAnswers(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true},
+		{ID: 2, Selector: "checkbox", Weight: 10, Options: []string{"AWS", "GCP"}},
+		{ID: 3, Selector: "dropdown", Weight: 10, Options: []string{"Low", "High"}},
+		{ID: 4, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true},
+		{ID: 5, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}},
+	}
+	answers := map[int]interface{}{
+		1:  42.0,
+		2:  []interface{}{"AWS", 3.0},
+		3:  "Extreme",
+		99: "Yes",
+	}
+
+	errs := controllers.ValidateAnswers(answers, questions)
+	want := []struct {
+		id   int
+		code string
+	}{
+		{1, controllers.CodeWrongType},
+		{2, controllers.CodeWrongType},
+		{3, controllers.CodeInvalidOption},
+		{4, controllers.CodeMissingAnswer},
+		{99, controllers.CodeUnknownQuestion},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].QuestionID != w.id || errs[i].Code != w.code {
+			t.Errorf("error %d: expected question %d %s, got %+v", i, w.id, w.code, errs[i])
+		}
+	}
+}
+
+func TestValidateAnswersAcceptsValidPayload(t *testing.T) {
+	questions := []models.Question{
+		{ID: 1, Selector: "radio", Weight: 10, Options: []string{"Yes", "No"}, Required: true},
+		{ID: 2, Selector: "checkbox", Weight: 10, Options: []string{"AWS", "GCP"}},
+	}
+	answers := map[int]interface{}{1: "No", 2: []interface{}{"GCP"}}
+
+	if errs := controllers.ValidateAnswers(answers, questions); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %+v", errs)
+	}
+}
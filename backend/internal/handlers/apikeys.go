@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+// ErrInvalidAPIKey is returned when an API key to create fails validation.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// CreateAPIKey validates k and stores it for the tenant of ctx with a new
+// id and secret. The key returned is the only copy of the secret; only its
+// hash is stored. Validation failures wrap ErrInvalidAPIKey.
+func (h *Handler) CreateAPIKey(ctx context.Context, k models.APIKey) (models.APIKey, string, error) {
+	k.CreatedAt = time.Now().UTC()
+	if err := controllers.ValidateAPIKey(k, k.CreatedAt); err != nil {
+		return k, "", fmt.Errorf("%w: %v", ErrInvalidAPIKey, err)
+	}
+	id, key, err := controllers.NewAPIKey()
+	if err != nil {
+		return k, "", err
+	}
+	k.ID = id
+	k.Tenant = tenant.FromContext(ctx)
+	k.SecretHash = controllers.HashAPIKey(key)
+	if err := h.repos.APIKeys.CreateAPIKey(ctx, k); err != nil {
+		return k, "", err
+	}
+	return k, key, nil
+}
+
+// APIKeys lists the API keys of the tenant of ctx, revoked and expired ones
+// included.
+func (h *Handler) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	return h.repos.APIKeys.ListAPIKeys(ctx)
+}
+
+// RevokeAPIKey revokes an API key of the tenant of ctx; requests made with
+// it are rejected from then on.
+func (h *Handler) RevokeAPIKey(ctx context.Context, id string) error {
+	return h.repos.APIKeys.RevokeAPIKey(ctx, id, time.Now().UTC())
+}
@@ -0,0 +1,245 @@
+package handlers_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
+)
+
+// testIssuer returns an authenticator trusting a freshly generated key for
+// an issuer with the given tenant claim, and a function signing claims with
+// that key.
+func testIssuer(t *testing.T, tenantClaim string) (*middleware.JWTAuthenticator, func(claims map[string]any) string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	enc := base64.RawURLEncoding.EncodeToString
+	pub, _ := key.PublicKey.Bytes()
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "EC", "kid": "k1", "crv": "P-256", "x": enc(pub[1:33]), "y": enc(pub[33:])},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	os.WriteFile(path, jwks, 0o600)
+	auth, err := middleware.NewJWTAuthenticator([]middleware.IssuerConfig{{Issuer: "https://idp.test/", JWKSFile: path, TenantClaim: tenantClaim}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+
+	return auth, func(claims map[string]any) string {
+		claims["iss"] = "https://idp.test/"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		header, _ := json.Marshal(map[string]string{"alg": "ES256", "kid": "k1"})
+		body, _ := json.Marshal(claims)
+		signed := enc(header) + "." + enc(body)
+		digest := sha256.Sum256([]byte(signed))
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed + "." + enc(append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...))
+	}
+}
+
+// applicantToken returns an authenticator trusting a freshly generated key
+// and a token from it for applicant sub.
+func applicantToken(t *testing.T, sub string) (*middleware.JWTAuthenticator, string) {
+	t.Helper()
+	auth, sign := testIssuer(t, "")
+	return auth, sign(map[string]any{"sub": sub, "roles": "applicant"})
+}
+
+func TestApplicantsOnlyReachTheirOwnAssessments(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	auth, token := applicantToken(t, "12")
+	schema, err := h.Schema()
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(middleware.Authenticate(auth))
+	r.HandleFunc("/submit", h.SubmitHandler)
+	r.HandleFunc("/assessments", h.StartAssessmentHandler)
+	r.Handle("/graphql", h.GraphqlHandler(schema, handlers.GraphqlConfig{}))
+	do := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("/submit", `{"userId": "12", "answers": {"1": "No"}}`); w.Code != http.StatusOK {
+		t.Errorf("expected applicants to submit for themselves, got %d: %s", w.Code, w.Body.String())
+	}
+	if w := do("/submit", `{"userId": "13", "answers": {"1": "No"}}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 submitting for another user, got %d", w.Code)
+	}
+	if w := do("/assessments", `{"userId": "13"}`); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 starting a draft for another user, got %d", w.Code)
+	}
+
+	query := func(q string) string {
+		data, _ := json.Marshal(map[string]string{"query": q})
+		return do("/graphql", string(data)).Body.String()
+	}
+	if body := query(`{ result(userId: "12") { totalScore } }`); !strings.Contains(body, `"totalScore"`) {
+		t.Errorf("expected the applicant's own result, got %s", body)
+	}
+	if body := query(`{ result(userId: "13") { totalScore } }`); !strings.Contains(body, handlers.ErrForbidden.Error()) {
+		t.Errorf("expected another user's result to be forbidden, got %s", body)
+	}
+	if body := query(`{ assessments(userId: "13") { submissionId } }`); !strings.Contains(body, handlers.ErrForbidden.Error()) {
+		t.Errorf("expected another user's history to be forbidden, got %s", body)
+	}
+}
+
+func TestBrokerKeysActWithinTheirScopes(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	ctx := context.Background()
+	_, key, err := h.CreateAPIKey(ctx, models.APIKey{Name: "Broker", Scopes: []string{"submit"}})
+	if err != nil {
+		t.Fatalf("failed to create API key: %v", err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(middleware.APIKeys(store))
+	r.Use(middleware.Tenant(store, middleware.TenantFromPrincipal, middleware.TenantFromHeader))
+	r.Handle("/submit", middleware.Require(middleware.PermSubmit)(http.HandlerFunc(h.SubmitHandler)))
+	r.Handle("/result/{userID}", middleware.RequireUser("userID")(http.HandlerFunc(h.ResultHandler)))
+	do := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(middleware.APIKeyHeader, key)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, user := range []string{"12", "13"} {
+		if w := do("POST", "/submit", `{"userId": "`+user+`", "answers": {"1": "No"}}`); w.Code != http.StatusOK {
+			t.Errorf("expected the broker to submit for user %s, got %d: %s", user, w.Code, w.Body.String())
+		}
+	}
+	if w := do("GET", "/result/12", ""); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 reading results without read:results, got %d", w.Code)
+	}
+	if w := do("POST", "/submit", `{"userId": "12", "answers": {"1": "No"}}`, middleware.TenantHeader, "acme"); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 naming another tenant than the key's, got %d", w.Code)
+	}
+}
+
+func TestTokensCannotSwitchTenants(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	ctx := context.Background()
+	for _, id := range []string{"acme", "globex"} {
+		if _, err := h.CreateTenant(ctx, models.Tenant{ID: id, Name: id}); err != nil {
+			t.Fatalf("failed to create tenant: %v", err)
+		}
+	}
+	store.SaveSubmission(tenant.WithID(ctx, "globex"), models.Submission{ID: "s1", UserID: "12"})
+
+	router := func(auth *middleware.JWTAuthenticator) *mux.Router {
+		r := mux.NewRouter()
+		r.Use(middleware.Authenticate(auth))
+		r.Use(middleware.Tenant(store, middleware.TenantFromPrincipal, middleware.TenantFromHeader))
+		r.Handle("/result/{userID}", middleware.RequireUser("userID")(http.HandlerFunc(h.ResultHandler)))
+		return r
+	}
+	do := func(r *mux.Router, token, header string) int {
+		req := httptest.NewRequest("GET", "/result/12", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		if header != "" {
+			req.Header.Set(middleware.TenantHeader, header)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Without a tenant claim, tokens are for the default tenant.
+	auth, sign := testIssuer(t, "")
+	underwriter := sign(map[string]any{"sub": "uw", "roles": "underwriter"})
+	if code := do(router(auth), underwriter, "globex"); code != http.StatusForbidden {
+		t.Errorf("expected 403 naming another tenant in the header, got %d", code)
+	}
+
+	auth, sign = testIssuer(t, "tenant")
+	r := router(auth)
+	if code := do(r, sign(map[string]any{"sub": "uw", "roles": "underwriter", "tenant": "acme"}), "globex"); code != http.StatusForbidden {
+		t.Errorf("expected 403 naming another tenant than the token's, got %d", code)
+	}
+	if code := do(r, sign(map[string]any{"sub": "uw", "roles": "underwriter"}), "globex"); code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a token without the tenant claim, got %d", code)
+	}
+	if code := do(r, sign(map[string]any{"sub": "uw", "roles": "underwriter", "tenant": "globex"}), ""); code != http.StatusOK {
+		t.Errorf("expected the token's own tenant to be readable, got %d", code)
+	}
+}
+
+func TestTenantAdminsCannotAdministerOtherTenants(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	if _, err := h.CreateTenant(context.Background(), models.Tenant{ID: "acme", Name: "Acme"}); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	auth, sign := testIssuer(t, "tenant")
+	r := mux.NewRouter()
+	r.Use(middleware.Authenticate(auth))
+	r.Use(middleware.Tenant(store, middleware.TenantFromPrincipal, middleware.TenantFromHeader))
+	r.Handle("/admin/tenants", middleware.RequireGlobalAdmin(http.HandlerFunc(h.AdminTenantsHandler)))
+
+	for tenantID, want := range map[string]int{"acme": http.StatusForbidden, "default": http.StatusOK} {
+		req := httptest.NewRequest("GET", "/admin/tenants", nil)
+		req.Header.Set("Authorization", "Bearer "+sign(map[string]any{"sub": "root", "roles": "admin", "tenant": tenantID}))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != want {
+			t.Errorf("expected %d for an admin of %s, got %d", want, tenantID, w.Code)
+		}
+	}
+}
+
+func TestUnauthenticatedRequestsAreRejected(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	auth, _ := applicantToken(t, "12")
+
+	r := mux.NewRouter()
+	r.Use(middleware.Authenticate(auth))
+	r.Handle("/result/{userID}", middleware.RequireUser("userID")(http.HandlerFunc(h.ResultHandler)))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/result/12", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("WWW-Authenticate") == "" {
+		t.Error("expected a WWW-Authenticate challenge")
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// currentTiers returns the stored tier table, or nil when none is stored.
+func (h *Handler) currentTiers(ctx context.Context) (*models.TierTable, error) {
+	tiers, err := h.repos.ScoringConfig.CurrentTiers(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tiers, nil
+}
+
+// ExportBundle returns the active catalog and the stored tier table.
+func (h *Handler) ExportBundle(ctx context.Context) (models.Bundle, error) {
+	ps, err := h.repos.Catalog.AllParadigms(ctx)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	qs, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	tiers, err := h.currentTiers(ctx)
+	if err != nil {
+		return models.Bundle{}, err
+	}
+	return controllers.BuildBundle(ps, qs, tiers), nil
+}
+
+// ImportBundle makes the stored catalog match b and returns the changes.
+// With dryRun set nothing is written.
+func (h *Handler) ImportBundle(ctx context.Context, b models.Bundle, dryRun bool) (models.CatalogPlan, error) {
+	ps, err := h.repos.Catalog.AllParadigms(ctx)
+	if err != nil {
+		return models.CatalogPlan{}, err
+	}
+	qs, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return models.CatalogPlan{}, err
+	}
+	tiers, err := h.currentTiers(ctx)
+	if err != nil {
+		return models.CatalogPlan{}, err
+	}
+	rules, err := h.repos.ScoringConfig.ListRules(ctx)
+	if err != nil {
+		return models.CatalogPlan{}, err
+	}
+
+	plan, err := controllers.PlanImport(ps, qs, tiers, rules, b)
+	if err != nil {
+		return models.CatalogPlan{}, invalidChange("%v", err)
+	}
+	if dryRun || len(plan.Changes) == 0 {
+		return plan, nil
+	}
+	return plan, h.repos.Catalog.ImportCatalog(ctx, plan)
+}
@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// ErrInvalidChange is returned when a catalog edit fails validation.
+var ErrInvalidChange = errors.New("invalid catalog change")
+
+// ErrRevisionRequired is returned when an edit does not say which revision
+// it was based on.
+var ErrRevisionRequired = errors.New("revision required")
+
+// OrderItem places one paradigm or question in a reorder request. Revision
+// is the revision the caller last read.
+type OrderItem struct {
+	ID       int `json:"id"`
+	Revision int `json:"revision"`
+}
+
+func invalidChange(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidChange, fmt.Sprintf(format, args...))
+}
+
+func (h *Handler) findParadigm(ctx context.Context, id int) (models.Paradigm, []models.Paradigm, error) {
+	all, err := h.repos.Catalog.AllParadigms(ctx)
+	if err != nil {
+		return models.Paradigm{}, nil, err
+	}
+	for _, p := range all {
+		if p.ID == id {
+			return p, all, nil
+		}
+	}
+	return models.Paradigm{}, all, repositories.ErrNotFound
+}
+
+func (h *Handler) findQuestion(ctx context.Context, id int) (models.Question, []models.Question, error) {
+	all, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return models.Question{}, nil, err
+	}
+	for _, q := range all {
+		if q.ID == id {
+			return q, all, nil
+		}
+	}
+	return models.Question{}, all, repositories.ErrNotFound
+}
+
+// CreateParadigm adds a paradigm. A zero Position places it last.
+func (h *Handler) CreateParadigm(ctx context.Context, p models.Paradigm) (models.Paradigm, error) {
+	if err := controllers.ValidateParadigm(p); err != nil {
+		return models.Paradigm{}, invalidChange("%v", err)
+	}
+	all, err := h.repos.Catalog.AllParadigms(ctx)
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	if p.Position == 0 {
+		for _, existing := range all {
+			p.Position = max(p.Position, existing.Position)
+		}
+		p.Position++
+	}
+	p.Retired = false
+	return h.repos.Catalog.CreateParadigm(ctx, p)
+}
+
+// UpdateParadigm replaces the name, description and position of p.ID.
+func (h *Handler) UpdateParadigm(ctx context.Context, p models.Paradigm) (models.Paradigm, error) {
+	if p.Revision == 0 {
+		return models.Paradigm{}, ErrRevisionRequired
+	}
+	if err := controllers.ValidateParadigm(p); err != nil {
+		return models.Paradigm{}, invalidChange("%v", err)
+	}
+	stored, _, err := h.findParadigm(ctx, p.ID)
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	if stored.Retired {
+		return models.Paradigm{}, invalidChange("paradigm %d is retired", p.ID)
+	}
+	updated, err := h.repos.Catalog.UpdateParadigms(ctx, []models.Paradigm{p})
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	return updated[0], nil
+}
+
+// RetireParadigm stops serving a paradigm. Its questions must have been
+// reassigned or retired first.
+func (h *Handler) RetireParadigm(ctx context.Context, id, revision int) (models.Paradigm, error) {
+	if revision == 0 {
+		return models.Paradigm{}, ErrRevisionRequired
+	}
+	p, _, err := h.findParadigm(ctx, id)
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	if p.Retired {
+		return models.Paradigm{}, invalidChange("paradigm %d is already retired", id)
+	}
+	qs, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	for _, q := range qs {
+		if !q.Retired && q.Paradigm == strconv.Itoa(id) {
+			return models.Paradigm{}, invalidChange("paradigm %d still has active question %d", id, q.ID)
+		}
+	}
+
+	p.Retired = true
+	p.Revision = revision
+	updated, err := h.repos.Catalog.UpdateParadigms(ctx, []models.Paradigm{p})
+	if err != nil {
+		return models.Paradigm{}, err
+	}
+	return updated[0], nil
+}
+
+// ReorderParadigms sets the order of the active paradigms. order must list
+// each of them exactly once.
+func (h *Handler) ReorderParadigms(ctx context.Context, order []OrderItem) ([]models.Paradigm, error) {
+	all, err := h.repos.Catalog.AllParadigms(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := map[int]models.Paradigm{}
+	for _, p := range all {
+		if !p.Retired {
+			active[p.ID] = p
+		}
+	}
+	if err := checkOrder(order, len(active), func(id int) bool { _, ok := active[id]; return ok }); err != nil {
+		return nil, err
+	}
+
+	ps := make([]models.Paradigm, len(order))
+	for i, item := range order {
+		ps[i] = active[item.ID]
+		ps[i].Position = i + 1
+		ps[i].Revision = item.Revision
+	}
+	return h.repos.Catalog.UpdateParadigms(ctx, ps)
+}
+
+// checkOrder checks that order names every active row exactly once.
+func checkOrder(order []OrderItem, active int, isActive func(id int) bool) error {
+	seen := map[int]bool{}
+	for _, item := range order {
+		if !isActive(item.ID) {
+			return invalidChange("%d is not an active entry", item.ID)
+		}
+		if seen[item.ID] {
+			return invalidChange("%d is listed twice", item.ID)
+		}
+		if item.Revision == 0 {
+			return ErrRevisionRequired
+		}
+		seen[item.ID] = true
+	}
+	if len(order) != active {
+		return invalidChange("order lists %d of %d active entries", len(order), active)
+	}
+	return nil
+}
+
+// checkQuestion validates q and the active catalog it would produce.
+func (h *Handler) checkQuestion(ctx context.Context, q models.Question, all []models.Question) error {
+	if err := controllers.ValidateQuestion(q); err != nil {
+		return invalidChange("%v", err)
+	}
+
+	paradigmID, err := strconv.Atoi(q.Paradigm)
+	if err != nil {
+		return invalidChange("unknown paradigm %q", q.Paradigm)
+	}
+	p, _, err := h.findParadigm(ctx, paradigmID)
+	if errors.Is(err, repositories.ErrNotFound) || p.Retired {
+		return invalidChange("unknown paradigm %q", q.Paradigm)
+	}
+	if err != nil {
+		return err
+	}
+
+	catalog := []models.Question{}
+	for _, existing := range all {
+		if !existing.Retired && existing.ID != q.ID {
+			catalog = append(catalog, existing)
+		}
+	}
+	if err := controllers.ValidateCatalog(append(catalog, q)); err != nil {
+		return invalidChange("%v", err)
+	}
+	return nil
+}
+
+// CreateQuestion adds a question to the live catalog. A zero Position
+// places it last.
+func (h *Handler) CreateQuestion(ctx context.Context, q models.Question) (models.Question, error) {
+	all, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return models.Question{}, err
+	}
+	q.ID = 0
+	q.Retired = false
+	if err := h.checkQuestion(ctx, q, all); err != nil {
+		return models.Question{}, err
+	}
+	if q.Position == 0 {
+		for _, existing := range all {
+			q.Position = max(q.Position, existing.Position)
+		}
+		q.Position++
+	}
+	return h.repos.Catalog.CreateQuestion(ctx, q)
+}
+
+// UpdateQuestion replaces every field of q.ID, including its options,
+// weight and paradigm.
+func (h *Handler) UpdateQuestion(ctx context.Context, q models.Question) (models.Question, error) {
+	if q.Revision == 0 {
+		return models.Question{}, ErrRevisionRequired
+	}
+	stored, all, err := h.findQuestion(ctx, q.ID)
+	if err != nil {
+		return models.Question{}, err
+	}
+	if stored.Retired {
+		return models.Question{}, invalidChange("question %d is retired", q.ID)
+	}
+	if err := h.checkQuestion(ctx, q, all); err != nil {
+		return models.Question{}, err
+	}
+	updated, err := h.repos.Catalog.UpdateQuestions(ctx, []models.Question{q})
+	if err != nil {
+		return models.Question{}, err
+	}
+	return updated[0], nil
+}
+
+// AssignQuestion moves a question to another paradigm.
+func (h *Handler) AssignQuestion(ctx context.Context, id, revision int, paradigm string) (models.Question, error) {
+	q, _, err := h.findQuestion(ctx, id)
+	if err != nil {
+		return models.Question{}, err
+	}
+	q.Paradigm = paradigm
+	q.Revision = revision
+	return h.UpdateQuestion(ctx, q)
+}
+
+// RetireQuestion stops asking a question. No active question or
+// underwriting rule may depend on it.
+func (h *Handler) RetireQuestion(ctx context.Context, id, revision int) (models.Question, error) {
+	if revision == 0 {
+		return models.Question{}, ErrRevisionRequired
+	}
+	q, all, err := h.findQuestion(ctx, id)
+	if err != nil {
+		return models.Question{}, err
+	}
+	if q.Retired {
+		return models.Question{}, invalidChange("question %d is already retired", id)
+	}
+	for _, other := range all {
+		for _, c := range other.Conditions {
+			if !other.Retired && c.QuestionID == id {
+				return models.Question{}, invalidChange("question %d has a condition on question %d", other.ID, id)
+			}
+		}
+	}
+	for _, rule := range controllers.CurrentRules() {
+		for _, c := range rule.When {
+			if c.QuestionID == id {
+				return models.Question{}, invalidChange("underwriting rule %q depends on question %d", rule.ID, id)
+			}
+		}
+	}
+
+	q.Retired = true
+	q.Revision = revision
+	updated, err := h.repos.Catalog.UpdateQuestions(ctx, []models.Question{q})
+	if err != nil {
+		return models.Question{}, err
+	}
+	return updated[0], nil
+}
+
+// ReorderQuestions sets the order of the active questions. order must list
+// each of them exactly once.
+func (h *Handler) ReorderQuestions(ctx context.Context, order []OrderItem) ([]models.Question, error) {
+	all, err := h.repos.Catalog.AllQuestions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active := map[int]models.Question{}
+	for _, q := range all {
+		if !q.Retired {
+			active[q.ID] = q
+		}
+	}
+	if err := checkOrder(order, len(active), func(id int) bool { _, ok := active[id]; return ok }); err != nil {
+		return nil, err
+	}
+
+	qs := make([]models.Question, len(order))
+	for i, item := range order {
+		qs[i] = active[item.ID]
+		qs[i].Position = i + 1
+		qs[i].Revision = item.Revision
+	}
+	return h.repos.Catalog.UpdateQuestions(ctx, qs)
+}
+
+// requestRevision reads the revision an edit is based on from If-Match,
+// falling back to the revision in the payload.
+func requestRevision(r *http.Request, fallback int) int {
+	etag := strings.TrimPrefix(r.Header.Get("If-Match"), "W/")
+	if rev, err := strconv.Atoi(strings.Trim(etag, `"`)); err == nil {
+		return rev
+	}
+	return fallback
+}
+
+func writeCatalogJSON(w http.ResponseWriter, status, revision int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if revision > 0 {
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, revision))
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeCatalogError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidChange):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, ErrRevisionRequired):
+		http.Error(w, "If-Match header or revision required", http.StatusPreconditionRequired)
+	case errors.Is(err, repositories.ErrConflict):
+		http.Error(w, "Revision conflict, reload and retry", http.StatusPreconditionFailed)
+	case errors.Is(err, repositories.ErrNotFound):
+		http.Error(w, "Not found", http.StatusNotFound)
+	default:
+		http.Error(w, "Database error", http.StatusInternalServerError)
+	}
+}
+
+func pathID(r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	return id, err == nil
+}
+
+// AdminParadigmsHandler lists every paradigm, retired ones included.
+func (h *Handler) AdminParadigmsHandler(w http.ResponseWriter, r *http.Request) {
+	ps, err := h.repos.Catalog.AllParadigms(r.Context())
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, 0, ps)
+}
+
+// AdminParadigmHandler returns one paradigm with its revision as ETag.
+func (h *Handler) AdminParadigmHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "Invalid paradigm id", http.StatusBadRequest)
+		return
+	}
+	p, _, err := h.findParadigm(r.Context(), id)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, p.Revision, p)
+}
+
+func (h *Handler) CreateParadigmHandler(w http.ResponseWriter, r *http.Request) {
+	var p models.Paradigm
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	p, err := h.CreateParadigm(r.Context(), p)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusCreated, p.Revision, p)
+}
+
+func (h *Handler) UpdateParadigmHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	var p models.Paradigm
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil || !ok {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	p.ID = id
+	p.Revision = requestRevision(r, p.Revision)
+	p, err := h.UpdateParadigm(r.Context(), p)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, p.Revision, p)
+}
+
+func (h *Handler) RetireParadigmHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "Invalid paradigm id", http.StatusBadRequest)
+		return
+	}
+	p, err := h.RetireParadigm(r.Context(), id, requestRevision(r, 0))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, p.Revision, p)
+}
+
+// ReorderParadigmsHandler takes every active paradigm, in the new order, as
+// a JSON array of {"id", "revision"}.
+func (h *Handler) ReorderParadigmsHandler(w http.ResponseWriter, r *http.Request) {
+	var order []OrderItem
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	ps, err := h.ReorderParadigms(r.Context(), order)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, 0, ps)
+}
+
+// AdminQuestionsHandler lists every question, retired ones included.
+func (h *Handler) AdminQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	qs, err := h.repos.Catalog.AllQuestions(r.Context())
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, 0, qs)
+}
+
+// AdminQuestionHandler returns one question with its revision as ETag.
+func (h *Handler) AdminQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "Invalid question id", http.StatusBadRequest)
+		return
+	}
+	q, _, err := h.findQuestion(r.Context(), id)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, q.Revision, q)
+}
+
+func (h *Handler) CreateQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	var q models.Question
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	q, err := h.CreateQuestion(r.Context(), q)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusCreated, q.Revision, q)
+}
+
+func (h *Handler) UpdateQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	var q models.Question
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil || !ok {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	q.ID = id
+	q.Revision = requestRevision(r, q.Revision)
+	q, err := h.UpdateQuestion(r.Context(), q)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, q.Revision, q)
+}
+
+// AssignQuestionHandler moves a question to the paradigm in the payload.
+func (h *Handler) AssignQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	var payload struct {
+		Paradigm string `json:"paradigm"`
+		Revision int    `json:"revision"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || !ok {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	q, err := h.AssignQuestion(r.Context(), id, requestRevision(r, payload.Revision), payload.Paradigm)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, q.Revision, q)
+}
+
+func (h *Handler) RetireQuestionHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(r)
+	if !ok {
+		http.Error(w, "Invalid question id", http.StatusBadRequest)
+		return
+	}
+	q, err := h.RetireQuestion(r.Context(), id, requestRevision(r, 0))
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, q.Revision, q)
+}
+
+// ReorderQuestionsHandler takes every active question, in the new order, as
+// a JSON array of {"id", "revision"}.
+func (h *Handler) ReorderQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	var order []OrderItem
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	qs, err := h.ReorderQuestions(r.Context(), order)
+	if err != nil {
+		writeCatalogError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, 0, qs)
+}
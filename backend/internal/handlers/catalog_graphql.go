@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/graphql-go/graphql"
+
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+)
+
+var errAdminRequired = errors.New("admin access required")
+
+// decodeArg converts a GraphQL argument into the struct its JSON fields
+// describe.
+func decodeArg(arg interface{}, v interface{}) error {
+	data, err := json.Marshal(arg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// adminOnly wraps a resolver so it only runs for admin requests. Only
+// global admins edit catalogs, of whichever tenant the request is for.
+func adminOnly(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if !middleware.IsGlobalAdmin(p.Context) {
+			return nil, errAdminRequired
+		}
+		return resolve(p)
+	}
+}
+
+var idRevisionArgs = graphql.FieldConfigArgument{
+	"id":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+	"revision": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+}
+
+func withArgs(base graphql.FieldConfigArgument, extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for k, v := range base {
+		args[k] = v
+	}
+	for k, v := range extra {
+		args[k] = v
+	}
+	return args
+}
+
+// catalogMutations are the admin edits of paradigms and questions.
+func (h *Handler) catalogMutations() graphql.Fields {
+	orderArgs := graphql.FieldConfigArgument{
+		"order": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(models.OrderInputType)))},
+	}
+
+	return graphql.Fields{
+		"createParadigm": &graphql.Field{
+			Type: models.ParadigmType,
+			Args: graphql.FieldConfigArgument{
+				"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(models.ParadigmInputType)},
+			},
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var para models.Paradigm
+				if err := decodeArg(p.Args["input"], &para); err != nil {
+					return nil, err
+				}
+				return h.CreateParadigm(p.Context, para)
+			}),
+		},
+		"updateParadigm": &graphql.Field{
+			Type: models.ParadigmType,
+			Args: withArgs(idRevisionArgs, graphql.FieldConfigArgument{
+				"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(models.ParadigmInputType)},
+			}),
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var para models.Paradigm
+				if err := decodeArg(p.Args["input"], &para); err != nil {
+					return nil, err
+				}
+				para.ID = p.Args["id"].(int)
+				para.Revision = p.Args["revision"].(int)
+				return h.UpdateParadigm(p.Context, para)
+			}),
+		},
+		"retireParadigm": &graphql.Field{
+			Type: models.ParadigmType,
+			Args: idRevisionArgs,
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				return h.RetireParadigm(p.Context, p.Args["id"].(int), p.Args["revision"].(int))
+			}),
+		},
+		"reorderParadigms": &graphql.Field{
+			Type: graphql.NewList(models.ParadigmType),
+			Args: orderArgs,
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var order []OrderItem
+				if err := decodeArg(p.Args["order"], &order); err != nil {
+					return nil, err
+				}
+				return h.ReorderParadigms(p.Context, order)
+			}),
+		},
+		"createQuestion": &graphql.Field{
+			Type: models.QuestionType,
+			Args: graphql.FieldConfigArgument{
+				"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(models.QuestionInputType)},
+			},
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var q models.Question
+				if err := decodeArg(p.Args["input"], &q); err != nil {
+					return nil, err
+				}
+				return h.CreateQuestion(p.Context, q)
+			}),
+		},
+		"updateQuestion": &graphql.Field{
+			Type: models.QuestionType,
+			Args: withArgs(idRevisionArgs, graphql.FieldConfigArgument{
+				"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(models.QuestionInputType)},
+			}),
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var q models.Question
+				if err := decodeArg(p.Args["input"], &q); err != nil {
+					return nil, err
+				}
+				q.ID = p.Args["id"].(int)
+				q.Revision = p.Args["revision"].(int)
+				return h.UpdateQuestion(p.Context, q)
+			}),
+		},
+		"assignQuestion": &graphql.Field{
+			Type: models.QuestionType,
+			Args: withArgs(idRevisionArgs, graphql.FieldConfigArgument{
+				"paradigm": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			}),
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				return h.AssignQuestion(p.Context, p.Args["id"].(int), p.Args["revision"].(int), p.Args["paradigm"].(string))
+			}),
+		},
+		"retireQuestion": &graphql.Field{
+			Type: models.QuestionType,
+			Args: idRevisionArgs,
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				return h.RetireQuestion(p.Context, p.Args["id"].(int), p.Args["revision"].(int))
+			}),
+		},
+		"reorderQuestions": &graphql.Field{
+			Type: graphql.NewList(models.QuestionType),
+			Args: orderArgs,
+			Resolve: adminOnly(func(p graphql.ResolveParams) (interface{}, error) {
+				var order []OrderItem
+				if err := decodeArg(p.Args["order"], &order); err != nil {
+					return nil, err
+				}
+				return h.ReorderQuestions(p.Context, order)
+			}),
+		},
+	}
+}
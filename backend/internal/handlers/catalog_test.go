@@ -0,0 +1,185 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+func catalogStore() *repositories.Memory {
+	store := repositories.NewMemory()
+	store.SetParadigms([]models.Paradigm{{ID: 1, Name: "Threat", Position: 1}, {ID: 2, Name: "Vulnerability", Position: 2}})
+	store.SetQuestions([]models.Question{
+		{ID: 1, Paradigm: "1", Text: "Do you use MFA?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10, Position: 1},
+		{ID: 2, Paradigm: "1", Text: "Do you use SSO?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10, Position: 2,
+			Conditions: []models.Condition{{QuestionID: 1, AnyOf: []string{"Yes"}}}},
+	})
+	return store
+}
+
+func catalogRequest(method, path string, vars map[string]string, body any) *http.Request {
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	return mux.SetURLVars(req, vars)
+}
+
+func TestUpdateQuestionRevisions(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	edit := map[string]any{"paradigm": "2", "text": "Do you enforce MFA?", "selector": "radio", "options": []string{"Yes", "No"}, "weight": 20}
+
+	w := httptest.NewRecorder()
+	h.UpdateQuestionHandler(w, catalogRequest("PUT", "/admin/questions/1", map[string]string{"id": "1"}, edit))
+	if w.Code != http.StatusPreconditionRequired {
+		t.Fatalf("expected 428 without a revision, got %d", w.Code)
+	}
+
+	req := catalogRequest("PUT", "/admin/questions/1", map[string]string{"id": "1"}, edit)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	h.UpdateQuestionHandler(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Fatalf("expected 200 with ETag \"2\", got %d %q: %s", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+	var q models.Question
+	json.NewDecoder(w.Body).Decode(&q)
+	if q.Paradigm != "2" || q.Weight != 20 {
+		t.Errorf("expected the edit to be stored, got %+v", q)
+	}
+
+	// Replaying the same edit is based on a stale revision.
+	req = catalogRequest("PUT", "/admin/questions/1", map[string]string{"id": "1"}, edit)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	h.UpdateQuestionHandler(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected 412 for a stale revision, got %d", w.Code)
+	}
+}
+
+func TestCatalogChangesAreValidated(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+
+	cases := []struct {
+		name string
+		body map[string]any
+	}{
+		{"weight out of range", map[string]any{"paradigm": "1", "text": "Backups?", "selector": "radio", "options": []string{"Yes"}, "weight": 500}},
+		{"no options", map[string]any{"paradigm": "1", "text": "Backups?", "selector": "radio", "weight": 5}},
+		{"unknown selector", map[string]any{"paradigm": "1", "text": "Backups?", "selector": "slider", "options": []string{"Yes"}, "weight": 5}},
+		{"unknown paradigm", map[string]any{"paradigm": "9", "text": "Backups?", "selector": "radio", "options": []string{"Yes"}, "weight": 5}},
+		{"dangling condition", map[string]any{"paradigm": "1", "text": "Backups?", "selector": "radio", "options": []string{"Yes"}, "weight": 5,
+			"conditions": []map[string]any{{"questionId": 42, "anyOf": []string{"Yes"}}}}},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		h.CreateQuestionHandler(w, catalogRequest("POST", "/admin/questions", nil, tc.body))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("%s: expected 422, got %d: %s", tc.name, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.CreateQuestionHandler(w, catalogRequest("POST", "/admin/questions", nil,
+		map[string]any{"paradigm": "2", "text": "Backups?", "selector": "radio", "options": []string{"Yes", "No"}, "weight": 5}))
+	var q models.Question
+	json.NewDecoder(w.Body).Decode(&q)
+	if w.Code != http.StatusCreated || q.ID != 3 || q.Position != 3 || q.Revision != 1 {
+		t.Errorf("expected question 3 to be created last, got %d: %+v", w.Code, q)
+	}
+}
+
+func TestRetireChecksDependents(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+
+	req := catalogRequest("DELETE", "/admin/questions/1", map[string]string{"id": "1"}, nil)
+	req.Header.Set("If-Match", `"1"`)
+	w := httptest.NewRecorder()
+	h.RetireQuestionHandler(w, req)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), "question 2") {
+		t.Fatalf("expected question 2's condition to block retiring question 1, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req = catalogRequest("DELETE", "/admin/paradigms/1", map[string]string{"id": "1"}, nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	h.RetireParadigmHandler(w, req)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected active questions to block retiring paradigm 1, got %d", w.Code)
+	}
+
+	req = catalogRequest("DELETE", "/admin/questions/2", map[string]string{"id": "2"}, nil)
+	req.Header.Set("If-Match", `"1"`)
+	w = httptest.NewRecorder()
+	h.RetireQuestionHandler(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected question 2 to be retired, got %d: %s", w.Code, w.Body.String())
+	}
+	live, _ := store.ListQuestions(req.Context())
+	if len(live) != 1 || live[0].ID != 1 {
+		t.Errorf("expected only question 1 to stay live, got %+v", live)
+	}
+}
+
+func TestReorderQuestions(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+
+	w := httptest.NewRecorder()
+	h.ReorderQuestionsHandler(w, catalogRequest("PUT", "/admin/questions/order", nil, []map[string]int{{"id": 2, "revision": 1}}))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected an incomplete order to be rejected, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ReorderQuestionsHandler(w, catalogRequest("PUT", "/admin/questions/order", nil,
+		[]map[string]int{{"id": 2, "revision": 1}, {"id": 1, "revision": 1}}))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	live, _ := store.ListQuestions(httptest.NewRequest("GET", "/", nil).Context())
+	if live[0].ID != 2 || live[1].ID != 1 {
+		t.Errorf("expected question 2 first, got %+v", live)
+	}
+}
+
+func TestCatalogMutationsRequireAdmin(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	schema, err := h.Schema()
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	srv := middleware.AdminToken("s3cret")(h.GraphqlHandler(schema, handlers.GraphqlConfig{Pretty: true}))
+	query := map[string]string{"query": `mutation { createParadigm(input: {name: "Resilience"}) { id name revision } }`}
+
+	for _, tc := range []struct {
+		token string
+		want  string
+	}{
+		{"", "admin access required"},
+		{"wrong", "admin access required"},
+		{"s3cret", `"name": "Resilience"`},
+	} {
+		data, _ := json.Marshal(query)
+		req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, req)
+		if !strings.Contains(w.Body.String(), tc.want) {
+			t.Errorf("token %q: expected %s in %s", tc.token, tc.want, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+func init() {
+	models.ParadigmType.AddFieldConfig("questions", &graphql.Field{
+		Type:        graphql.NewList(models.QuestionType),
+		Description: "Questions of the current questionnaire in this paradigm",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			para, _ := p.Source.(models.Paradigm)
+			l, err := loadersFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+			return l.Questions(p.Context, para.ID)
+		},
+	})
+	models.QuestionType.AddFieldConfig("paradigm", &graphql.Field{
+		Type: models.ParadigmType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			q, _ := p.Source.(models.Question)
+			l, err := loadersFrom(p.Context)
+			if err != nil {
+				return nil, err
+			}
+			id, err := strconv.Atoi(q.Paradigm)
+			if err != nil {
+				return nil, nil
+			}
+			// Retired paradigms still resolve for questions kept in older
+			// questionnaire versions.
+			para, ok, err := l.Paradigm(p.Context, id)
+			if !ok || err != nil {
+				return nil, err
+			}
+			return para, nil
+		},
+	})
+}
+
+var (
+	errPersistedQueryNotFound   = errors.New("PersistedQueryNotFound")
+	errPersistedQueryNotAllowed = errors.New("PersistedQueryNotAllowed")
+	errPersistedQueryMismatch   = errors.New("provided sha256Hash does not match query")
+)
+
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+	Extensions    struct {
+		PersistedQuery *struct {
+			SHA256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// decodeGraphqlRequest reads a request sent as GET parameters, as a JSON
+// body or as an application/graphql body.
+func decodeGraphqlRequest(r *http.Request) (graphqlRequest, error) {
+	var req graphqlRequest
+	if r.Method == http.MethodGet {
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				return req, err
+			}
+		}
+		if ext := q.Get("extensions"); ext != "" {
+			if err := json.Unmarshal([]byte(ext), &req.Extensions); err != nil {
+				return req, err
+			}
+		}
+		return req, nil
+	}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/graphql") {
+		body, err := io.ReadAll(r.Body)
+		req.Query = string(body)
+		return req, err
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	return req, err
+}
+
+// query returns the text to run, looking persisted queries up by hash.
+func (req graphqlRequest) query(cfg GraphqlConfig) (string, error) {
+	query := req.Query
+	if pq := req.Extensions.PersistedQuery; pq != nil {
+		hash := strings.ToLower(pq.SHA256Hash)
+		if query == "" {
+			var ok bool
+			if query, ok = cfg.PersistedQueries[hash]; !ok {
+				return "", errPersistedQueryNotFound
+			}
+		} else if QueryHash(query) != hash {
+			return "", errPersistedQueryMismatch
+		}
+	}
+	if cfg.PersistedOnly {
+		if _, ok := cfg.PersistedQueries[QueryHash(query)]; !ok {
+			return "", errPersistedQueryNotAllowed
+		}
+	}
+	return query, nil
+}
+
+// GraphqlHandler serves schema within the limits of cfg. Each request gets
+// its own loaders for nested fields.
+func (h *Handler) GraphqlHandler(schema graphql.Schema, cfg GraphqlConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := decodeGraphqlRequest(r)
+		if err != nil {
+			http.Error(w, "Invalid GraphQL request", http.StatusBadRequest)
+			return
+		}
+
+		res := h.executeGraphql(withLoaders(r.Context(), h), &schema, cfg, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		if cfg.Pretty {
+			enc.SetIndent("", "\t")
+		}
+		enc.Encode(res)
+	})
+}
+
+func (h *Handler) executeGraphql(ctx context.Context, schema *graphql.Schema, cfg GraphqlConfig, req graphqlRequest) *graphql.Result {
+	query, err := req.query(cfg)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if vr := graphql.ValidateDocument(schema, doc, nil); !vr.IsValid {
+		return &graphql.Result{Errors: vr.Errors}
+	}
+
+	depth, complexity := queryCost(schema, doc, req.OperationName)
+	if cfg.MaxDepth > 0 && depth > cfg.MaxDepth {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query depth %d exceeds the limit of %d", depth, cfg.MaxDepth))}
+	}
+	if cfg.MaxComplexity > 0 && complexity > cfg.MaxComplexity {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, cfg.MaxComplexity))}
+	}
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        *schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+var submitPayloadType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "SubmitAssessmentPayload",
+	Fields: graphql.Fields{
+		"result": &graphql.Field{Type: models.ResultType},
+		"errors": &graphql.Field{Type: graphql.NewList(models.AnswerErrorType)},
+	},
+})
+
+// answersArg converts AnswerInput values into the answer map /submit
+// decodes from JSON.
+func answersArg(arg interface{}) map[int]interface{} {
+	answers := map[int]interface{}{}
+	list, _ := arg.([]interface{})
+	for _, item := range list {
+		in, _ := item.(map[string]interface{})
+		id, _ := in["questionId"].(int)
+		switch {
+		case in["values"] != nil:
+			answers[id] = in["values"]
+		case in["number"] != nil:
+			answers[id] = in["number"]
+		default:
+			answers[id] = in["value"]
+		}
+	}
+	return answers
+}
+
+// withoutTrace drops the per-question trace unless explain is set, like
+// ?explain=true does for REST.
+func withoutTrace(p graphql.ResolveParams, res models.Result) models.Result {
+	if explain, _ := p.Args["explain"].(bool); !explain {
+		res.Trace = nil
+	}
+	return res
+}
+
+var explainArg = &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false}
+
+// Schema builds the GraphQL schema resolved through h. It covers everything
+// the REST API serves to applicants, plus the admin catalog mutations.
+func (h *Handler) Schema() (graphql.Schema, error) {
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "RootQuery",
+			Fields: graphql.Fields{
+				"questions": &graphql.Field{
+					Type:        graphql.NewList(models.QuestionType),
+					Description: "Questions of the current questionnaire",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						l, err := loadersFrom(p.Context)
+						if err != nil {
+							return nil, err
+						}
+						qn, err := l.CurrentQuestionnaire(p.Context)
+						return qn.Questions, err
+					},
+				},
+				"paradigms": &graphql.Field{
+					Type: graphql.NewList(models.ParadigmType),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return h.repos.Paradigms.ListParadigms(p.Context)
+					},
+				},
+				"paradigm": &graphql.Field{
+					Type: models.ParadigmType,
+					Args: graphql.FieldConfigArgument{
+						"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						l, err := loadersFrom(p.Context)
+						if err != nil {
+							return nil, err
+						}
+						para, ok, err := l.Paradigm(p.Context, p.Args["id"].(int))
+						if !ok || para.Retired || err != nil {
+							return nil, err
+						}
+						return para, nil
+					},
+				},
+				"result": &graphql.Field{
+					Type:        models.ResultType,
+					Description: "The result of the user's latest submission",
+					Args: graphql.FieldConfigArgument{
+						"userId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+						"explain": explainArg,
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if !middleware.Allowed(p.Context, middleware.PermReadResults) || !middleware.AllowedFor(p.Context, p.Args["userId"].(string)) {
+							return nil, ErrForbidden
+						}
+						res, err := h.repos.Results.LatestResult(p.Context, p.Args["userId"].(string))
+						if errors.Is(err, repositories.ErrNotFound) {
+							return nil, nil
+						}
+						if err != nil {
+							return nil, err
+						}
+						return withoutTrace(p, res), nil
+					},
+				},
+				"assessments": &graphql.Field{
+					Type:        graphql.NewList(models.AssessmentType),
+					Description: "The user's assessments, oldest first",
+					Args: graphql.FieldConfigArgument{
+						"userId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						if !middleware.Allowed(p.Context, middleware.PermReadResults) || !middleware.AllowedFor(p.Context, p.Args["userId"].(string)) {
+							return nil, ErrForbidden
+						}
+						subs, err := h.repos.Submissions.UserSubmissions(p.Context, p.Args["userId"].(string))
+						if err != nil {
+							return nil, err
+						}
+						return controllers.AssessmentHistory(subs), nil
+					},
+				},
+				"policies": &graphql.Field{
+					Type:        models.TierTableType,
+					Description: "The policy tier table currently used for scoring",
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return h.TierTable(p.Context)
+					},
+				},
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "RootMutation",
+			Fields: withFields(h.assessmentMutations(), h.catalogMutations()),
+		}),
+	})
+}
+
+// assessmentMutations are available to applicants.
+func (h *Handler) assessmentMutations() graphql.Fields {
+	return graphql.Fields{
+		"submitAssessment": &graphql.Field{
+			Type:        submitPayloadType,
+			Description: "Scores and stores answers exactly like POST /submit. Invalid answers are returned in errors and nothing is stored.",
+			Args: graphql.FieldConfigArgument{
+				"userId":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				"answers": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(models.AnswerInputType)))},
+				"explain": explainArg,
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if !middleware.Allowed(p.Context, middleware.PermSubmit) || !middleware.AllowedFor(p.Context, p.Args["userId"].(string)) {
+					return nil, ErrForbidden
+				}
+				res, errs, err := h.SubmitAssessment(p.Context, p.Args["userId"].(string), answersArg(p.Args["answers"]))
+				if errors.Is(err, ErrInvalidAnswers) {
+					return map[string]interface{}{"errors": errs}, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				return map[string]interface{}{"result": withoutTrace(p, res), "errors": []models.AnswerError{}}, nil
+			},
+		},
+	}
+}
+
+func withFields(sets ...graphql.Fields) graphql.Fields {
+	fields := graphql.Fields{}
+	for _, set := range sets {
+		for k, v := range set {
+			fields[k] = v
+		}
+	}
+	return fields
+}
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// listCostFactor is the number of items a list field is assumed to return
+// when estimating the complexity of the fields selected below it.
+const listCostFactor = 10
+
+// GraphqlConfig limits what the GraphQL endpoint executes. Zero limits
+// disable the corresponding check.
+type GraphqlConfig struct {
+	// MaxDepth bounds how deeply fields may be nested; root fields are at
+	// depth 1.
+	MaxDepth int
+	// MaxComplexity bounds the estimated number of fields resolved. Every
+	// field costs 1, and fields below a list are counted listCostFactor
+	// times.
+	MaxComplexity int
+	// PersistedQueries maps the hex SHA-256 of a query to its text.
+	// Clients send the hash in extensions.persistedQuery.sha256Hash instead
+	// of the query.
+	PersistedQueries map[string]string
+	// PersistedOnly rejects any query that is not in PersistedQueries.
+	PersistedOnly bool
+	// Pretty indents responses.
+	Pretty bool
+}
+
+// QueryHash returns the hash a persisted query is registered under.
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadPersistedQueries reads an allow-list of persisted queries: either a
+// JSON object of hash to query, or a JSON array of queries to hash.
+func LoadPersistedQueries(r io.Reader) (map[string]string, error) {
+	var raw json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, err
+	}
+	queries := map[string]string{}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		for _, q := range list {
+			queries[QueryHash(q)] = q
+		}
+		return queries, nil
+	}
+	if err := json.Unmarshal(raw, &queries); err != nil {
+		return nil, err
+	}
+	for hash, q := range queries {
+		if QueryHash(q) != strings.ToLower(hash) {
+			return nil, fmt.Errorf("persisted query %s does not match its hash", hash)
+		}
+	}
+	return queries, nil
+}
+
+// queryCost measures the depth and estimated complexity of the operation
+// the request runs. The document must already be validated against schema.
+func queryCost(schema *graphql.Schema, doc *ast.Document, operationName string) (depth, complexity int) {
+	c := costWalker{schema: schema, fragments: map[string]*ast.FragmentDefinition{}}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.FragmentDefinition:
+			c.fragments[def.Name.Value] = def
+		case *ast.OperationDefinition:
+			if op == nil && (operationName == "" || def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		}
+	}
+	if op == nil {
+		return 0, 0
+	}
+	root := schema.QueryType()
+	switch op.Operation {
+	case ast.OperationTypeMutation:
+		root = schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = schema.SubscriptionType()
+	}
+	return c.selectionSet(root, op.SelectionSet, 1)
+}
+
+type costWalker struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+}
+
+func (c costWalker) selectionSet(parent graphql.Type, set *ast.SelectionSet, depth int) (maxDepth, cost int) {
+	if set == nil {
+		return depth - 1, 0
+	}
+	maxDepth = depth - 1
+	for _, sel := range set.Selections {
+		d, n := 0, 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			// Introspection is bounded by the schema itself.
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			d, n = c.field(parent, sel, depth)
+		case *ast.InlineFragment:
+			t := parent
+			if sel.TypeCondition != nil {
+				t = c.schema.Type(sel.TypeCondition.Name.Value)
+			}
+			d, n = c.selectionSet(t, sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			frag, ok := c.fragments[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			d, n = c.selectionSet(c.schema.Type(frag.TypeCondition.Name.Value), frag.SelectionSet, depth)
+		}
+		maxDepth = max(maxDepth, d)
+		cost += n
+	}
+	return maxDepth, cost
+}
+
+func (c costWalker) field(parent graphql.Type, f *ast.Field, depth int) (maxDepth, cost int) {
+	var fields graphql.FieldDefinitionMap
+	switch parent := parent.(type) {
+	case *graphql.Object:
+		fields = parent.Fields()
+	case *graphql.Interface:
+		fields = parent.Fields()
+	}
+	def, ok := fields[f.Name.Value]
+	if !ok || f.SelectionSet == nil {
+		return depth, 1
+	}
+
+	t, list := def.Type, false
+	if nn, ok := t.(*graphql.NonNull); ok {
+		t = nn.OfType
+	}
+	if l, ok := t.(*graphql.List); ok {
+		t, list = l.OfType, true
+		if nn, ok := t.(*graphql.NonNull); ok {
+			t = nn.OfType
+		}
+	}
+	maxDepth, cost = c.selectionSet(t, f.SelectionSet, depth+1)
+	if list {
+		cost *= listCostFactor
+	}
+	return maxDepth, 1 + cost
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+
+	"cyber-go/internal/models"
+)
+
+// lazy fetches a value at most once.
+type lazy[T any] struct {
+	once sync.Once
+	v    T
+	err  error
+}
+
+func (l *lazy[T]) get(fetch func() (T, error)) (T, error) {
+	l.once.Do(func() { l.v, l.err = fetch() })
+	return l.v, l.err
+}
+
+// loaders batch the reads behind nested GraphQL fields. One set is made
+// per request, so a query over every paradigm and its questions costs one
+// questionnaire read and one paradigm read rather than one per row.
+type loaders struct {
+	h             *Handler
+	questionnaire lazy[models.Questionnaire]
+	byParadigm    lazy[map[string][]models.Question]
+	paradigms     lazy[map[int]models.Paradigm]
+}
+
+type loadersKey struct{}
+
+func withLoaders(ctx context.Context, h *Handler) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{h: h})
+}
+
+// loadersFrom returns the loaders of the GraphQL request ctx belongs to.
+func loadersFrom(ctx context.Context) (*loaders, error) {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return nil, errors.New("graphql request not served by a Handler")
+	}
+	return l, nil
+}
+
+// CurrentQuestionnaire is read once per request.
+func (l *loaders) CurrentQuestionnaire(ctx context.Context) (models.Questionnaire, error) {
+	return l.questionnaire.get(func() (models.Questionnaire, error) {
+		return l.h.CurrentQuestionnaire(ctx)
+	})
+}
+
+// Questions returns the current questions of a paradigm.
+func (l *loaders) Questions(ctx context.Context, paradigmID int) ([]models.Question, error) {
+	byParadigm, err := l.byParadigm.get(func() (map[string][]models.Question, error) {
+		qn, err := l.CurrentQuestionnaire(ctx)
+		if err != nil {
+			return nil, err
+		}
+		byParadigm := map[string][]models.Question{}
+		for _, q := range qn.Questions {
+			byParadigm[q.Paradigm] = append(byParadigm[q.Paradigm], q)
+		}
+		return byParadigm, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	qs := byParadigm[strconv.Itoa(paradigmID)]
+	if qs == nil {
+		qs = []models.Question{}
+	}
+	return qs, nil
+}
+
+// Paradigm returns a paradigm by id, retired or not.
+func (l *loaders) Paradigm(ctx context.Context, id int) (models.Paradigm, bool, error) {
+	byID, err := l.paradigms.get(func() (map[int]models.Paradigm, error) {
+		ps, err := l.h.repos.Catalog.AllParadigms(ctx)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[int]models.Paradigm, len(ps))
+		for _, p := range ps {
+			byID[p.ID] = p
+		}
+		return byID, nil
+	})
+	p, ok := byID[id]
+	return p, ok, err
+}
@@ -0,0 +1,231 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+func graphqlPost(t *testing.T, h *handlers.Handler, cfg handlers.GraphqlConfig, body any) graphqlResponse {
+	t.Helper()
+	schema, err := h.Schema()
+	if err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	data, _ := json.Marshal(body)
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	h.GraphqlHandler(schema, cfg).ServeHTTP(w, req)
+
+	var res graphqlResponse
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return res
+}
+
+func graphqlQuery(t *testing.T, h *handlers.Handler, query string) graphqlResponse {
+	t.Helper()
+	res := graphqlPost(t, h, handlers.GraphqlConfig{}, map[string]string{"query": query})
+	if len(res.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", res.Errors)
+	}
+	return res
+}
+
+func graphqlError(res graphqlResponse) string {
+	if len(res.Errors) == 0 {
+		return ""
+	}
+	return res.Errors[0].Message
+}
+
+func TestGraphqlNestedParadigms(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	res := graphqlQuery(t, h, `{ paradigms { id name questions { id paradigmId paradigm { name } } } }`)
+
+	var paradigms []struct {
+		ID        int
+		Name      string
+		Questions []struct {
+			ID         int
+			ParadigmID int
+			Paradigm   struct{ Name string }
+		}
+	}
+	if err := json.Unmarshal(res.Data["paradigms"], &paradigms); err != nil {
+		t.Fatalf("failed to decode paradigms: %v", err)
+	}
+	if len(paradigms) != 2 || len(paradigms[0].Questions) != 2 || len(paradigms[1].Questions) != 0 {
+		t.Fatalf("expected both questions under the first paradigm, got %+v", paradigms)
+	}
+	q := paradigms[0].Questions[0]
+	if q.ParadigmID != 1 || q.Paradigm.Name != "Threat" {
+		t.Errorf("expected paradigmId 1 resolving to Threat, got %+v", q)
+	}
+}
+
+func TestGraphqlSubmitAssessment(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+
+	res := graphqlQuery(t, h, `mutation { submitAssessment(userId: "12", answers: [{questionId: 1, value: "Maybe"}]) { result { totalScore } errors { questionId code } } }`)
+	var invalid struct {
+		Result *struct{ TotalScore int }
+		Errors []struct {
+			QuestionID int
+			Code       string
+		}
+	}
+	json.Unmarshal(res.Data["submitAssessment"], &invalid)
+	if invalid.Result != nil || len(invalid.Errors) != 1 || invalid.Errors[0].QuestionID != 1 {
+		t.Fatalf("expected an answer error for question 1, got %+v", invalid)
+	}
+
+	res = graphqlQuery(t, h, `mutation { submitAssessment(userId: "12", answers: [{questionId: 1, value: "Yes"}, {questionId: 2, value: "Yes"}], explain: true) { result { submissionId totalScore trace { questionId answer } } errors { code } } }`)
+	var submitted struct {
+		Result struct {
+			SubmissionID string
+			TotalScore   int
+			Trace        []struct {
+				QuestionID int
+				Answer     string
+			}
+		}
+		Errors []struct{ Code string }
+	}
+	json.Unmarshal(res.Data["submitAssessment"], &submitted)
+	if submitted.Result.SubmissionID == "" || submitted.Result.TotalScore != 20 || len(submitted.Errors) != 0 {
+		t.Fatalf("expected a stored result scoring 20, got %+v", submitted)
+	}
+	if len(submitted.Result.Trace) != 2 || submitted.Result.Trace[0].Answer != `"Yes"` {
+		t.Errorf("expected the trace with JSON answers, got %+v", submitted.Result.Trace)
+	}
+
+	res = graphqlQuery(t, h, `{ result(userId: "12") { submissionId trace { questionId } } assessments(userId: "12") { submissionId totalScore } }`)
+	var latest struct {
+		SubmissionID string
+		Trace        []struct{ QuestionID int }
+	}
+	json.Unmarshal(res.Data["result"], &latest)
+	if latest.SubmissionID != submitted.Result.SubmissionID || len(latest.Trace) != 0 {
+		t.Errorf("expected the latest result without trace, got %+v", latest)
+	}
+	var history []struct {
+		SubmissionID string
+		TotalScore   int
+	}
+	json.Unmarshal(res.Data["assessments"], &history)
+	if len(history) != 1 || history[0].TotalScore != 20 {
+		t.Errorf("expected one assessment in the history, got %+v", history)
+	}
+}
+
+type countingQuestions struct {
+	repositories.QuestionRepository
+	calls int
+}
+
+func (c *countingQuestions) ListQuestions(ctx context.Context) ([]models.Question, error) {
+	c.calls++
+	return c.QuestionRepository.ListQuestions(ctx)
+}
+
+type countingCatalog struct {
+	repositories.CatalogRepository
+	calls int
+}
+
+func (c *countingCatalog) AllParadigms(ctx context.Context) ([]models.Paradigm, error) {
+	c.calls++
+	return c.CatalogRepository.AllParadigms(ctx)
+}
+
+func TestGraphqlLoadersBatchNestedReads(t *testing.T) {
+	repos := repositories.From(catalogStore())
+	questions := &countingQuestions{QuestionRepository: repos.Questions}
+	catalog := &countingCatalog{CatalogRepository: repos.Catalog}
+	repos.Questions, repos.Catalog = questions, catalog
+	h := handlers.New(repos)
+
+	graphqlQuery(t, h, `{ questions { id } paradigms { questions { id paradigm { name questions { id } } } } }`)
+	if questions.calls != 1 || catalog.calls != 1 {
+		t.Errorf("expected one read of questions and paradigms per request, got %d and %d", questions.calls, catalog.calls)
+	}
+
+	graphqlQuery(t, h, `{ questions { id } }`)
+	if questions.calls != 2 {
+		t.Errorf("expected loaders not to be shared between requests, got %d reads", questions.calls)
+	}
+}
+
+func TestGraphqlQueryLimits(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	query := `query Nested { paradigms { questions { paradigm { name } } } } query Flat { paradigms { name } }`
+
+	for _, tc := range []struct {
+		cfg       handlers.GraphqlConfig
+		operation string
+		want      string
+	}{
+		{handlers.GraphqlConfig{MaxDepth: 3}, "Nested", "query depth 4 exceeds the limit of 3"},
+		{handlers.GraphqlConfig{MaxDepth: 4}, "Nested", ""},
+		{handlers.GraphqlConfig{MaxDepth: 1}, "Flat", "query depth 2 exceeds the limit of 1"},
+		// 1 + 10*(1 + 10*(1 + 1))
+		{handlers.GraphqlConfig{MaxComplexity: 200}, "Nested", "query complexity 211 exceeds the limit of 200"},
+		{handlers.GraphqlConfig{MaxComplexity: 211}, "Nested", ""},
+	} {
+		res := graphqlPost(t, h, tc.cfg, map[string]string{"query": query, "operationName": tc.operation})
+		if got := graphqlError(res); got != tc.want {
+			t.Errorf("%+v %s: expected error %q, got %q", tc.cfg, tc.operation, tc.want, got)
+		}
+	}
+}
+
+func TestGraphqlPersistedQueries(t *testing.T) {
+	h := handlers.New(repositories.From(catalogStore()))
+	allowed := `{ paradigms { name } }`
+	queries, err := handlers.LoadPersistedQueries(strings.NewReader(`["{ paradigms { name } }"]`))
+	if err != nil {
+		t.Fatalf("failed to load persisted queries: %v", err)
+	}
+	cfg := handlers.GraphqlConfig{PersistedQueries: queries, PersistedOnly: true}
+	persisted := func(hash string) map[string]any {
+		return map[string]any{"persistedQuery": map[string]any{"version": 1, "sha256Hash": hash}}
+	}
+
+	for _, tc := range []struct {
+		name string
+		body map[string]any
+		want string
+	}{
+		{"hash only", map[string]any{"extensions": persisted(handlers.QueryHash(allowed))}, ""},
+		{"allowed text", map[string]any{"query": allowed}, ""},
+		{"other text", map[string]any{"query": `{ questions { id } }`}, "PersistedQueryNotAllowed"},
+		{"unknown hash", map[string]any{"extensions": persisted(handlers.QueryHash("{ policies { version } }"))}, "PersistedQueryNotFound"},
+		{"wrong hash", map[string]any{"query": allowed, "extensions": persisted(handlers.QueryHash("x"))}, "provided sha256Hash does not match query"},
+	} {
+		res := graphqlPost(t, h, cfg, tc.body)
+		if got := graphqlError(res); got != tc.want {
+			t.Errorf("%s: expected error %q, got %q", tc.name, tc.want, got)
+		}
+	}
+
+	if _, err := handlers.LoadPersistedQueries(strings.NewReader(`{"abc": "{ paradigms { name } }"}`)); err == nil {
+		t.Errorf("expected a hash that does not match its query to be rejected")
+	}
+}
@@ -1,203 +1,337 @@
 package handlers
 
 import (
-	"database/sql"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"fmt" // You need to import fmt for Sprintf
+	"errors"
+	"io"
 	"net/http"
-	"strings"
-	"sync"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
-	"github.com/graphql-go/graphql"
-	"github.com/graphql-go/handler"
 
 	"cyber-go/internal/controllers"
+	"cyber-go/internal/middleware"
 	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
 	"cyber-go/internal/util"
 
 	"go.uber.org/zap"
 )
 
-var DB *sql.DB
+// Handler serves the HTTP and GraphQL API on top of the repositories it
+// is constructed with.
+type Handler struct {
+	repos     repositories.Repositories
+	draftTTL  time.Duration
+	configTTL time.Duration
+	config    *configCache
+}
 
-// SetDB is a public function to set the database connection for the handlers.
-func SetDB(conn *sql.DB) {
-	DB = conn
+// New returns a Handler using repos for all storage.
+func New(repos repositories.Repositories) *Handler {
+	return &Handler{repos: repos, draftTTL: DefaultDraftTTL, configTTL: DefaultConfigTTL, config: newConfigCache()}
 }
 
-var results = struct {
-	sync.RWMutex
-	data map[string]models.Result
-}{data: make(map[string]models.Result)}
+// GetParadigmsHandler responds with every paradigm as JSON.
+func (h *Handler) GetParadigmsHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := h.repos.Paradigms.ListParadigms(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
 
-// ParadigmFetcher defines a fetcher function that returns paradigms from DB or mock
-type ParadigmFetcher func() ([]map[string]interface{}, error)
+// GetQuestionsHandler returns the questions to ask next. Conditional
+// questions are only included once the answers passed as JSON in the optional
+// ?answers= parameter make them visible.
+func (h *Handler) GetQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	answers := map[int]interface{}{}
+	if raw := r.URL.Query().Get("answers"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &answers); err != nil {
+			http.Error(w, "Invalid answers parameter", http.StatusBadRequest)
+			return
+		}
+	}
 
-func GetQuestionsFromDB() ([]models.Question, error) {
-	rows, err := DB.Query("SELECT id, paradigm_id, text, selector, options, weight FROM questions")
+	qn, err := h.CurrentQuestionnaire(r.Context())
 	if err != nil {
-		return nil, err
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
 	}
-	defer rows.Close()
+	qs := controllers.VisibleQuestions(qn.Questions, answers)
 
-	var questions []models.Question
-	for rows.Next() {
-		var q models.Question
-		var paradigmID int
-		var opts string
-		if err := rows.Scan(&q.ID, &paradigmID, &q.Text, &q.Selector, &opts, &q.Weight); err != nil {
-			return nil, err
-		}
-		q.Options = strings.Split(opts, ",")
-		q.Paradigm = fmt.Sprintf("%d", paradigmID)
-		questions = append(questions, q)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(qs)
+}
+
+// ErrForbidden is returned when the caller may not act on a user's
+// assessments; see middleware.AllowedFor.
+var ErrForbidden = errors.New("not allowed for this user")
+
+// ErrInvalidAnswers is returned by SubmitAssessment when answers fail
+// validation; nothing is scored or stored.
+var ErrInvalidAnswers = errors.New("invalid answers")
+
+// scoreSubmission validates answers against qn and scores them with tiers
+// into a submission ready to store.
+func scoreSubmission(qn models.Questionnaire, tiers models.TierTable, userID string, answers map[int]interface{}) (models.Submission, []models.AnswerError, error) {
+	qs := qn.Questions
+
+	// Reject malformed answers before anything is scored or stored
+	if errs := controllers.ValidateAnswers(answers, qs); len(errs) > 0 {
+		return models.Submission{}, errs, ErrInvalidAnswers
 	}
-	return questions, nil
+
+	result := controllers.EvaluateAnswersWith(answers, qs, tiers)
+
+	// Convert values for DB insertion
+	result.SubmissionID = uuid.New().String()
+	result.QuestionnaireVersion = qn.Version
+	result.SubmittedAt = time.Now().UTC()
+
+	return models.Submission{
+		ID:                   result.SubmissionID,
+		UserID:               userID,
+		QuestionnaireVersion: qn.Version,
+		Answers:              answers,
+		Result:               result,
+		CreatedAt:            result.SubmittedAt,
+	}, nil, nil
 }
 
-// GetParadigmsHandler returns an HTTP handler that responds with JSON from the fetcher
-func GetParadigmsHandler(fetch ParadigmFetcher) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		data, err := fetch()
-		if err != nil {
-			http.Error(w, "Database query error", http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(data)
+func logSubmission(sub models.Submission, err error) {
+	if err != nil {
+		util.Logger.Error("failed to save submission",
+			zap.String("transactionID", sub.ID),
+			zap.String("userID", sub.UserID),
+			zap.Error(err),
+		)
+		return
 	}
+	util.Logger.Info("saved submission",
+		zap.String("transactionID", sub.ID),
+		zap.String("userID", sub.UserID),
+		zap.Int("score", sub.Result.TotalScore),
+		zap.String("policy", sub.Result.Policy),
+		zap.Int("questionnaireVersion", sub.QuestionnaireVersion),
+	)
 }
 
-// GraphqlHandler returns a GraphQL HTTP handler for the provided schema
-func GraphqlHandler(schema graphql.Schema) http.Handler {
-	return handler.New(&handler.Config{
-		Schema: &schema,
-		Pretty: true,
-	})
+// ErrIdempotencyMismatch is returned when an Idempotency-Key is reused for
+// a different request.
+var ErrIdempotencyMismatch = errors.New("idempotency key reused with a different request")
+
+// withRepos returns a copy of h using repos, such as those of a transaction.
+func (h *Handler) withRepos(repos repositories.Repositories) *Handler {
+	c := *h
+	c.repos = repos
+	return &c
 }
 
-func GetQuestionsHandler(w http.ResponseWriter, r *http.Request) {
-	qs, err := GetQuestionsFromDB()
+// submit scores answers against the current questionnaire and stores the
+// submission.
+func (h *Handler) submit(ctx context.Context, userID string, answers map[int]interface{}) (models.Submission, []models.AnswerError, error) {
+	// Score against the current questionnaire version
+	qn, err := h.CurrentQuestionnaire(ctx)
 	if err != nil {
-		http.Error(w, "Database query error", http.StatusInternalServerError)
-		return
+		return models.Submission{}, nil, err
+	}
+	tiers, err := h.TierTable(ctx)
+	if err != nil {
+		return models.Submission{}, nil, err
+	}
+	sub, errs, err := scoreSubmission(qn, tiers, userID, answers)
+	if err != nil {
+		return sub, errs, err
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(qs)
+	err = h.repos.Submissions.SaveSubmission(ctx, sub)
+	logSubmission(sub, err)
+	return sub, nil, err
 }
 
-func SubmitHandler(w http.ResponseWriter, r *http.Request) {
+// SubmitAssessment scores answers against the current questionnaire and
+// stores the submission, reading the questions and storing the result in
+// one transaction. Validation failures are returned alongside
+// ErrInvalidAnswers.
+func (h *Handler) SubmitAssessment(ctx context.Context, userID string, answers map[int]interface{}) (models.Result, []models.AnswerError, error) {
+	var sub models.Submission
+	var errs []models.AnswerError
+	err := h.repos.Tx.InTx(ctx, func(repos repositories.Repositories) error {
+		var err error
+		sub, errs, err = h.withRepos(repos).submit(ctx, userID, answers)
+		return err
+	})
+	if err != nil {
+		return models.Result{}, errs, err
+	}
+	return sub.Result, nil, nil
+}
+
+// SubmitIdempotent is SubmitAssessment for a request sent with an
+// Idempotency-Key. The response to the first successful request is stored
+// in the submission's transaction and returned for every retry with the
+// same requestHash; replayed reports whether it was. A retry with another
+// requestHash fails with ErrIdempotencyMismatch.
+func (h *Handler) SubmitIdempotent(ctx context.Context, key, requestHash, userID string, answers map[int]interface{}, explain bool) (rec models.IdempotencyRecord, replayed bool, errs []models.AnswerError, err error) {
+	err = h.repos.Tx.InTx(ctx, func(repos repositories.Repositories) error {
+		stored, err := repos.Idempotency.GetIdempotencyRecord(ctx, key)
+		if err == nil {
+			rec, replayed = stored, true
+			return nil
+		}
+		if !errors.Is(err, repositories.ErrNotFound) {
+			return err
+		}
+
+		sub, verrs, err := h.withRepos(repos).submit(ctx, userID, answers)
+		if err != nil {
+			errs = verrs
+			return err
+		}
+		if !explain {
+			sub.Result.Trace = nil
+		}
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(sub.Result); err != nil {
+			return err
+		}
+		rec = models.IdempotencyRecord{
+			Key:          key,
+			RequestHash:  requestHash,
+			Status:       http.StatusOK,
+			Response:     body.Bytes(),
+			SubmissionID: sub.ID,
+			CreatedAt:    sub.CreatedAt,
+		}
+		return repos.Idempotency.SaveIdempotencyRecord(ctx, rec)
+	})
+	if errors.Is(err, repositories.ErrConflict) {
+		// A concurrent request with the same key committed first; this
+		// one was rolled back.
+		rec, err = h.repos.Idempotency.GetIdempotencyRecord(ctx, key)
+		replayed = true
+	}
+	if err != nil {
+		return rec, false, errs, err
+	}
+	if rec.RequestHash != requestHash {
+		return rec, false, nil, ErrIdempotencyMismatch
+	}
+	return rec, replayed, nil, nil
+}
+
+func (h *Handler) SubmitHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
 	var payload struct {
 		Answers map[int]interface{} `json:"answers"`
 		UserID  string              `json:"userId"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+	if err := json.Unmarshal(body, &payload); err != nil {
 		http.Error(w, "Invalid payload", http.StatusBadRequest)
 		return
 	}
-
-	// Fetch all questions from DB
-	rows, err := DB.Query("SELECT id, paradigm_id, text, selector, options, weight FROM questions")
-	if err != nil {
-		http.Error(w, "Database query error", http.StatusInternalServerError)
+	if !middleware.AllowedFor(r.Context(), payload.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
 		return
 	}
-	defer rows.Close()
 
-	var qs []models.Question
-	for rows.Next() {
-		var q models.Question
-		var paradigmID int
-		var opts string
-		if err := rows.Scan(&q.ID, &paradigmID, &q.Text, &q.Selector, &opts, &q.Weight); err != nil {
-			http.Error(w, "Error scanning question", http.StatusInternalServerError)
-			return
-		}
-		q.Options = strings.Split(opts, ",")
-		q.Paradigm = fmt.Sprintf("%d", paradigmID)
-		qs = append(qs, q)
-	}
-
-	// Correctly process answers from JSON decoder
-	processedAnswers := make(map[int]interface{})
-	for id, ans := range payload.Answers {
-		if slice, ok := ans.([]interface{}); ok {
-			stringSlice := make([]string, len(slice))
-			for i, v := range slice {
-				stringSlice[i] = v.(string)
+	if key := r.Header.Get("Idempotency-Key"); key != "" {
+		// The query string is part of the request, as it decides
+		// whether the trace is included.
+		sum := sha256.Sum256(append([]byte(r.URL.RawQuery+"\n"), body...))
+		explain, _ := strconv.ParseBool(r.URL.Query().Get("explain"))
+		rec, replayed, errs, err := h.SubmitIdempotent(r.Context(), key, hex.EncodeToString(sum[:]), payload.UserID, payload.Answers, explain)
+		switch {
+		case errors.Is(err, ErrInvalidAnswers):
+			writeAnswerErrors(w, errs)
+		case errors.Is(err, ErrIdempotencyMismatch):
+			http.Error(w, "Idempotency-Key was already used for a different request", http.StatusConflict)
+		case err != nil:
+			http.Error(w, "Failed to save result", http.StatusInternalServerError)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			if replayed {
+				w.Header().Set("Idempotent-Replayed", "true")
 			}
-			processedAnswers[id] = stringSlice
-		} else {
-			processedAnswers[id] = ans
+			w.WriteHeader(rec.Status)
+			w.Write(rec.Response)
 		}
+		return
 	}
 
-	totalScore, policy := controllers.EvaluateAnswers(processedAnswers, qs)
-
-	// Convert values for DB insertion
-	transactionID := uuid.New().String()
-	score := int(totalScore) // or float64 if needed
-	policyStr := fmt.Sprintf("%v", policy)
-
-	_, err = DB.Exec(
-		"INSERT INTO results (user_id, score, policy) VALUES (?, ?, ?)",
-		payload.UserID, score, policyStr,
-	)
+	result, errs, err := h.SubmitAssessment(r.Context(), payload.UserID, payload.Answers)
+	if errors.Is(err, ErrInvalidAnswers) {
+		writeAnswerErrors(w, errs)
+		return
+	}
 	if err != nil {
-		util.Logger.Info("Transaction %s: failed to save result for user %s — error: %v",
-			zap.String("transactionID", transactionID),
-			zap.String("userID", payload.UserID),
-			zap.String("error", err.Error()),
-		)
 		http.Error(w, "Failed to save result", http.StatusInternalServerError)
 		return
 	}
 
-	util.Logger.Info("Transaction %s: saved result for user %s with score %d and policy '%s'",
-		zap.String("transactionID", transactionID),
-		zap.String("payload.UserID", payload.UserID),
-		zap.Int("score", score),
-		zap.String("policyStr", policyStr),
-	)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(explained(r, result))
+}
 
-	// Store result (simulate ETL)
-	results.Lock()
-	results.data[payload.UserID] = models.Result{TotalScore: totalScore, Policy: policy}
-	results.Unlock()
+// writeAnswerErrors responds 422 with the failures of invalid answers.
+func writeAnswerErrors(w http.ResponseWriter, errs []models.AnswerError) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(struct {
+		Error  string               `json:"error"`
+		Errors []models.AnswerError `json:"errors"`
+	}{Error: "invalid answers", Errors: errs})
+}
 
+// GetPoliciesHandler returns the policy tier table the tenant currently
+// scores with.
+func (h *Handler) GetPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	tiers, err := h.TierTable(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.Result{TotalScore: totalScore, Policy: policy})
+	json.NewEncoder(w).Encode(tiers)
 }
 
-func ResultHandler(w http.ResponseWriter, r *http.Request) {
+// ResultHandler returns the result of a user's latest submission.
+func (h *Handler) ResultHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userID := vars["userID"]
 
-	results.RLock()
-	res, ok := results.data[userID]
-	results.RUnlock()
-	if !ok {
+	result, err := h.repos.Results.LatestResult(r.Context(), userID)
+	if errors.Is(err, repositories.ErrNotFound) {
 		http.Error(w, "Result not found", http.StatusNotFound)
 		return
 	}
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	json.NewEncoder(w).Encode(explained(r, result))
 }
 
-var Schema, _ = graphql.NewSchema(graphql.SchemaConfig{
-	Query: graphql.NewObject(graphql.ObjectConfig{
-		Name: "RootQuery",
-		Fields: graphql.Fields{
-			"questions": &graphql.Field{
-				Type: graphql.NewList(models.QuestionType),
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-					return GetQuestionsFromDB()
-				},
-			},
-		},
-	}),
-})
+// explained drops the per-question trace unless the request asked for it
+// with ?explain=true.
+func explained(r *http.Request, res models.Result) models.Result {
+	if explain, _ := strconv.ParseBool(r.URL.Query().Get("explain")); !explain {
+		res.Trace = nil
+	}
+	return res
+}
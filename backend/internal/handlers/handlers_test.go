@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
 
 	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
 )
 
 func TestGetQuestionsHandler(t *testing.T) {
@@ -18,20 +22,22 @@ func TestGetQuestionsHandler(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	handlers.SetDB(db)
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
 
 	// Corrected: Add the missing "paradigm_id" column
-	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "weight"}).
-		AddRow(1, 101, "Question 1", "radio", "Yes,No", 10)
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil)
 
 	// Corrected: The mock query must also include "paradigm_id"
-	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, weight FROM questions").
+	// No questionnaire is published, so the live questions table is used.
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
 		WillReturnRows(rows)
 
 	req := httptest.NewRequest("GET", "/questions", nil)
 	w := httptest.NewRecorder()
 
-	handlers.GetQuestionsHandler(w, req)
+	h.GetQuestionsHandler(w, req)
 
 	res := w.Result()
 	defer res.Body.Close()
@@ -52,25 +58,30 @@ func TestSubmitHandler(t *testing.T) {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
 	}
 	defer db.Close()
-	handlers.SetDB(db)
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
 
 	// 2. Define expected database interactions
 	// This query must match the one in your handler exactly
-	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "weight"}).
-		AddRow(1, 101, "Question 1", "radio", "Yes,No", 10).
-		AddRow(2, 102, "Question 2", "checkbox", "AWS,GCP,Azure", 10).
-		AddRow(3, 103, "Question 3", "radio", "Yes,No", 15)
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+		AddRow(2, 102, "Question 2", "checkbox", "AWS,GCP,Azure", 0, 0, 10, false, nil, nil).
+		AddRow(3, 103, "Question 3", "radio", "Yes,No", 0, 0, 15, true, nil, nil)
 
 	// Mocks the database call that fetches all questions for evaluation.
-	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, weight FROM questions").
+	// No questionnaire is published, so the live questions table is used.
+	// The questions are read and the submission stored in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
 		WillReturnRows(rows)
 
 	// Mocks the database call that saves the result.
 	//mock.ExpectExec("INSERT INTO results").WillReturnResult(sqlmock.NewResult(1, 1))
 
-	mock.ExpectExec("INSERT INTO results").
-		WithArgs("12", sqlmock.AnyArg(), sqlmock.AnyArg()).
+	mock.ExpectExec("INSERT INTO submissions").
+		WithArgs(sqlmock.AnyArg(), "12", 0, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "accept", "[]", sqlmock.AnyArg(), sqlmock.AnyArg(), "default").
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 	// 3. Create and execute the HTTP request
 	// Correct payload format using a map for "answers"
 	payload := map[string]any{
@@ -87,7 +98,7 @@ func TestSubmitHandler(t *testing.T) {
 	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
-	handlers.SubmitHandler(w, req)
+	h.SubmitHandler(w, req)
 
 	// 4. Check the response
 	res := w.Result()
@@ -97,8 +108,213 @@ func TestSubmitHandler(t *testing.T) {
 		t.Fatalf("expected 200 OK, got %d", res.StatusCode)
 	}
 
+	var result models.Result
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		t.Fatalf("could not decode result: %v", err)
+	}
+	if len(result.Paradigms) != 3 {
+		t.Errorf("expected a breakdown for 3 paradigms, got %+v", result.Paradigms)
+	}
+	if result.Trace != nil {
+		t.Errorf("expected no trace without ?explain=true, got %+v", result.Trace)
+	}
+
 	// 5. Ensure all mock expectations were met
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("there were unfulfilled expectations: %s", err)
 	}
 }
+
+func TestSubmitHandlerRejectsInvalidAnswers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+		AddRow(2, 102, "Question 2", "checkbox", "AWS,GCP,Azure", 0, 0, 10, false, nil, nil)
+	// No questionnaire is published, so the live questions table is used.
+	// The questions are read and the submission stored in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
+		WillReturnRows(rows)
+	// No INSERT is expected: invalid submissions must not be stored.
+	mock.ExpectRollback()
+
+	payload := map[string]any{
+		"userId": "12",
+		"answers": map[string]any{
+			"1": 7,
+			"2": []any{"AWS", "Oracle"},
+		},
+	}
+	body, _ := json.Marshal(payload)
+	req := httptest.NewRequest("POST", "/submit", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.SubmitHandler(w, req)
+
+	res := w.Result()
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", res.StatusCode)
+	}
+
+	var resp struct {
+		Errors []models.AnswerError `json:"errors"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		t.Fatalf("could not decode errors: %v", err)
+	}
+	if len(resp.Errors) != 2 {
+		t.Fatalf("expected 2 answer errors, got %+v", resp.Errors)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSubmitHandlerExplain(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+		AddRow(2, 102, "Question 2", "checkbox", "AWS,GCP,Azure", 0, 0, 9, false, nil, nil)
+	// No questionnaire is published, so the live questions table is used.
+	// The questions are read and the submission stored in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
+		WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO submissions").
+		WithArgs(sqlmock.AnyArg(), "14", 0, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "accept", "[]", sqlmock.AnyArg(), sqlmock.AnyArg(), "default").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body, _ := json.Marshal(map[string]any{
+		"userId":  "14",
+		"answers": map[string]any{"1": "Yes", "2": []string{"AWS"}},
+	})
+	req := httptest.NewRequest("POST", "/submit?explain=true", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+
+	h.SubmitHandler(w, req)
+
+	var result models.Result
+	if err := json.NewDecoder(w.Result().Body).Decode(&result); err != nil {
+		t.Fatalf("could not decode result: %v", err)
+	}
+	if len(result.Trace) != 2 {
+		t.Fatalf("expected 2 trace entries, got %+v", result.Trace)
+	}
+	if result.Trace[0].Points != 10 || result.Trace[1].Points != 3 {
+		t.Errorf("unexpected trace points: %+v", result.Trace)
+	}
+}
+
+func TestGetQuestionsHandlerHidesConditionalQuestions(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		want  int
+	}{
+		{"/questions", 1},
+		{`/questions?answers={"1":"Yes"}`, 2},
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+		rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+			AddRow(1, 101, "Remote access?", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+			AddRow(2, 101, "MFA on remote access?", "radio", "Yes,No", 0, 0, 10, true, `[{"questionId":1,"anyOf":["Yes"]}]`, nil)
+		// No questionnaire is published, so the live questions table is used.
+		mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
+			WillReturnRows(rows)
+
+		req := httptest.NewRequest("GET", tc.query, nil)
+		w := httptest.NewRecorder()
+		h.GetQuestionsHandler(w, req)
+
+		var qs []models.Question
+		if err := json.NewDecoder(w.Result().Body).Decode(&qs); err != nil {
+			t.Fatalf("%s: could not decode questions: %v", tc.query, err)
+		}
+		if len(qs) != tc.want {
+			t.Errorf("%s: expected %d questions, got %d", tc.query, tc.want, len(qs))
+		}
+		db.Close()
+	}
+}
+
+func TestGetQuestionsHandlerReadsScoredOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Backups?", "checkbox", `[{"label":"Offline","score":6},{"label":"None","exclusive":true}]`, 1, 2, 10, true, nil, nil)
+	// No questionnaire is published, so the live questions table is used.
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
+		WillReturnRows(rows)
+
+	req := httptest.NewRequest("GET", "/questions", nil)
+	w := httptest.NewRecorder()
+	h.GetQuestionsHandler(w, req)
+
+	var qs []models.Question
+	if err := json.NewDecoder(w.Result().Body).Decode(&qs); err != nil {
+		t.Fatalf("could not decode questions: %v", err)
+	}
+	if len(qs) != 1 || len(qs[0].Choices) != 2 || qs[0].MaxSelections != 2 {
+		t.Fatalf("expected scored options to be loaded, got %+v", qs)
+	}
+	if qs[0].Options[0] != "Offline" || !qs[0].Choices[1].Exclusive {
+		t.Errorf("unexpected options: %+v", qs[0])
+	}
+}
+
+func TestHandlersWithMemoryStore(t *testing.T) {
+	store := repositories.NewMemory()
+	store.SetParadigms([]models.Paradigm{{ID: 1, Name: "Threat"}})
+	store.SetQuestions([]models.Question{{ID: 1, Paradigm: "1", Text: "Do you use MFA?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10}})
+	h := handlers.New(repositories.From(store))
+
+	w := httptest.NewRecorder()
+	h.GetParadigmsHandler(w, httptest.NewRequest("GET", "/paradigms", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Threat") {
+		t.Fatalf("expected paradigms, got %d: %s", w.Code, w.Body.String())
+	}
+
+	body, _ := json.Marshal(map[string]any{"userId": "12", "answers": map[string]any{"1": "Yes"}})
+	w = httptest.NewRecorder()
+	h.SubmitHandler(w, httptest.NewRequest("POST", "/submit", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/result/12", nil), map[string]string{"userID": "12"})
+	w = httptest.NewRecorder()
+	h.ResultHandler(w, req)
+	var res models.Result
+	json.NewDecoder(w.Body).Decode(&res)
+	if w.Code != http.StatusOK || res.TotalScore != 10 {
+		t.Errorf("expected stored result with score 10, got %d: %+v", w.Code, res)
+	}
+}
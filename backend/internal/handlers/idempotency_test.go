@@ -0,0 +1,111 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/repositories"
+)
+
+func idempotentSubmit(h *handlers.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/submit", bytes.NewReader([]byte(body)))
+	req.Header.Set("Idempotency-Key", key)
+	w := httptest.NewRecorder()
+	h.SubmitHandler(w, req)
+	return w
+}
+
+func TestSubmitHandlerIdempotencyKey(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	body := `{"userId": "12", "answers": {"1": "Yes", "2": "No"}}`
+
+	first := idempotentSubmit(h, "k-1", body)
+	if first.Code != http.StatusOK || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Fatalf("expected a fresh 200, got %d: %s", first.Code, first.Body.String())
+	}
+	retry := idempotentSubmit(h, "k-1", body)
+	if retry.Code != http.StatusOK || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("expected a replayed 200, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Body.String() != first.Body.String() {
+		t.Errorf("expected the stored response, got %s instead of %s", retry.Body.String(), first.Body.String())
+	}
+
+	if w := idempotentSubmit(h, "k-1", `{"userId": "12", "answers": {"1": "No"}}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a different body, got %d", w.Code)
+	}
+	if w := idempotentSubmit(h, "k-2", `{"userId": "12", "answers": {"1": "Maybe"}}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected invalid answers to be rejected, got %d", w.Code)
+	}
+	if w := idempotentSubmit(h, "k-2", body); w.Code != http.StatusOK || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("a rejected request must not claim its key, got %d", w.Code)
+	}
+
+	subs, _ := store.UserSubmissions(context.Background(), "12")
+	if len(subs) != 2 {
+		t.Errorf("expected one submission per key, got %d", len(subs))
+	}
+}
+
+func TestSubmitHandlerIdempotencyKeyRace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	recordColumns := []string{"key", "request_hash", "status", "response", "submission_id", "created_at"}
+	rows := sqlmock.NewRows([]string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}).
+		AddRow(1, 101, "Question 1", "radio", "Yes,No", 0, 0, 10, true, nil, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM idempotency_keys WHERE key = ").WithArgs("k-1", "default").WillReturnRows(sqlmock.NewRows(recordColumns))
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve FROM questions").
+		WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO submissions").WillReturnResult(sqlmock.NewResult(0, 1))
+	// A concurrent request with the same key committed first
+	mock.ExpectExec("INSERT INTO idempotency_keys (.+) ON CONFLICT").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+	mock.ExpectQuery("FROM idempotency_keys WHERE key = ").WithArgs("k-1", "default").
+		WillReturnRows(sqlmock.NewRows(recordColumns).AddRow("k-1", "other", 200, `{"totalScore":0}`, "s-0", time.Now()))
+
+	if w := idempotentSubmit(h, "k-1", `{"userId": "12", "answers": {"1": "Yes"}}`); w.Code != http.StatusConflict {
+		t.Errorf("expected the winner's different request to give 409, got %d", w.Code)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestSubmitHandlerStopsWhenCancelled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest("POST", "/submit", bytes.NewReader([]byte(`{"userId": "12", "answers": {"1": "Yes"}}`))).WithContext(ctx)
+	w := httptest.NewRecorder()
+	h.SubmitHandler(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected a cancelled request to fail, got %d", w.Code)
+	}
+	// No expectations: nothing may be read or written once cancelled.
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// CurrentQuestionnaire returns the version marked current. Until one is
+// published, the live questions table is served as version 0.
+func (h *Handler) CurrentQuestionnaire(ctx context.Context) (models.Questionnaire, error) {
+	qn, err := h.repos.Questionnaires.CurrentQuestionnaire(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		qs, err := h.repos.Questions.ListQuestions(ctx)
+		if err != nil {
+			return models.Questionnaire{}, err
+		}
+		return models.Questionnaire{Version: 0, Label: "unpublished", Current: true, Questions: qs}, nil
+	}
+	return qn, err
+}
+
+// Questionnaire returns a published version. Version 0 is the live
+// questions table, which is what the next published version would contain.
+func (h *Handler) Questionnaire(ctx context.Context, version int) (models.Questionnaire, error) {
+	if version == 0 {
+		qs, err := h.repos.Questions.ListQuestions(ctx)
+		if err != nil {
+			return models.Questionnaire{}, err
+		}
+		return models.Questionnaire{Version: 0, Label: "unpublished", Questions: qs}, nil
+	}
+	return h.repos.Questionnaires.GetQuestionnaire(ctx, version)
+}
+
+// GetQuestionnaireHandler returns a published questionnaire by version
+// number, or the current one for "current".
+func (h *Handler) GetQuestionnaireHandler(w http.ResponseWriter, r *http.Request) {
+	version := mux.Vars(r)["version"]
+
+	var qn models.Questionnaire
+	var err error
+	if version == "current" {
+		qn, err = h.CurrentQuestionnaire(r.Context())
+	} else {
+		id, convErr := strconv.Atoi(version)
+		if convErr != nil {
+			http.Error(w, "Invalid questionnaire version", http.StatusBadRequest)
+			return
+		}
+		qn, err = h.Questionnaire(r.Context(), id)
+	}
+	if errors.Is(err, repositories.ErrNotFound) {
+		http.Error(w, "Questionnaire not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(qn)
+}
+
+// PublishQuestionnaireHandler snapshots the live questions table into a new
+// immutable version. The new version is not made current.
+func (h *Handler) PublishQuestionnaireHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Label string `json:"label"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Label == "" {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	qs, err := h.repos.Questions.ListQuestions(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+	if len(qs) == 0 {
+		http.Error(w, "Cannot publish an empty questionnaire", http.StatusUnprocessableEntity)
+		return
+	}
+	if err := controllers.ValidateCatalog(qs); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	qn, err := h.repos.Questionnaires.PublishQuestionnaire(r.Context(), models.Questionnaire{Label: payload.Label, Questions: qs})
+	if err != nil {
+		http.Error(w, "Failed to publish questionnaire", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(qn)
+}
+
+// SetCurrentQuestionnaireHandler makes a published version the one used for
+// new submissions.
+func (h *Handler) SetCurrentQuestionnaireHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["version"])
+	if err != nil {
+		http.Error(w, "Invalid questionnaire version", http.StatusBadRequest)
+		return
+	}
+
+	err = h.repos.Questionnaires.SetCurrentQuestionnaire(r.Context(), id)
+	if errors.Is(err, repositories.ErrNotFound) {
+		http.Error(w, "Questionnaire not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+var questionnaireCols = []string{"id", "label", "is_current", "published_at", "catalog"}
+
+const publishedCatalog = `[{"id":1,"paradigm":"1","text":"MFA?","weight":10,"selector":"radio","options":["Yes","No"],"required":true}]`
+
+func TestSubmitHandlerRecordsQuestionnaireVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	// The questions are read and the submission stored in one transaction.
+	mock.ExpectBegin()
+	mock.ExpectQuery("FROM questionnaire_versions WHERE is_current").
+		WillReturnRows(sqlmock.NewRows(questionnaireCols).AddRow(3, "2026-Q3", true, time.Now(), publishedCatalog))
+	mock.ExpectExec("INSERT INTO submissions").
+		WithArgs(sqlmock.AnyArg(), "v-user", 3, sqlmock.AnyArg(), 10, sqlmock.AnyArg(), "accept", "[]", sqlmock.AnyArg(), sqlmock.AnyArg(), "default").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	body, _ := json.Marshal(map[string]any{"userId": "v-user", "answers": map[string]any{"1": "Yes"}})
+	w := httptest.NewRecorder()
+	h.SubmitHandler(w, httptest.NewRequest("POST", "/submit", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200 OK, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result models.Result
+	json.NewDecoder(w.Body).Decode(&result)
+	if result.QuestionnaireVersion != 3 {
+		t.Errorf("expected version 3 in the result, got %d", result.QuestionnaireVersion)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestGetQuestionnaireHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	mock.ExpectQuery("FROM questionnaire_versions WHERE id = ").WithArgs(2, "default").
+		WillReturnRows(sqlmock.NewRows(questionnaireCols).AddRow(2, "2026-Q2", false, time.Now(), publishedCatalog))
+	mock.ExpectQuery("FROM questionnaire_versions WHERE id = ").WithArgs(9, "default").
+		WillReturnRows(sqlmock.NewRows(questionnaireCols))
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/questionnaires/2", nil), map[string]string{"version": "2"})
+	w := httptest.NewRecorder()
+	h.GetQuestionnaireHandler(w, req)
+
+	var qn models.Questionnaire
+	if err := json.NewDecoder(w.Body).Decode(&qn); err != nil {
+		t.Fatalf("could not decode questionnaire: %v", err)
+	}
+	if qn.Version != 2 || qn.Current || len(qn.Questions) != 1 {
+		t.Errorf("unexpected questionnaire: %+v", qn)
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/questionnaires/9", nil), map[string]string{"version": "9"})
+	w = httptest.NewRecorder()
+	h.GetQuestionnaireHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown version, got %d", w.Code)
+	}
+}
+
+func TestSetCurrentQuestionnaireHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET is_current = FALSE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SET is_current = TRUE WHERE id = ").WithArgs(4, "default").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	req := mux.SetURLVars(httptest.NewRequest("PUT", "/questionnaires/4/current", nil), map[string]string{"version": "4"})
+	w := httptest.NewRecorder()
+	h.SetCurrentQuestionnaireHandler(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("SET is_current = FALSE").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("SET is_current = TRUE WHERE id = ").WithArgs(5, "default").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	req = mux.SetURLVars(httptest.NewRequest("PUT", "/questionnaires/5/current", nil), map[string]string{"version": "5"})
+	w = httptest.NewRecorder()
+	h.SetCurrentQuestionnaireHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown version, got %d", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPublishQuestionnaireRejectsInvalidCatalog(t *testing.T) {
+	store := repositories.NewMemory()
+	store.SetQuestions([]models.Question{
+		{ID: 1, Paradigm: "1", Text: "MFA?", Selector: "radio", Options: []string{"Yes", "No"},
+			Conditions: []models.Condition{{QuestionID: 2, AnyOf: []string{"Yes"}}}},
+		{ID: 2, Paradigm: "1", Text: "SSO?", Selector: "radio", Options: []string{"Yes", "No"},
+			Conditions: []models.Condition{{QuestionID: 1, AnyOf: []string{"Yes"}}}},
+	})
+	h := handlers.New(repositories.From(store))
+
+	w := httptest.NewRecorder()
+	h.PublishQuestionnaireHandler(w, httptest.NewRequest("POST", "/questionnaires", bytes.NewBufferString(`{"label": "2026-Q4"}`)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a catalog with a condition cycle, got %d: %s", w.Code, w.Body.String())
+	}
+}
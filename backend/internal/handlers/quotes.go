@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// QuoteHandler prices the latest result of a user for the given
+// organization profile and stores the quote against that submission.
+func (h *Handler) QuoteHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID  string            `json:"userId"`
+		Profile models.OrgProfile `json:"profile"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+	if !middleware.AllowedFor(r.Context(), payload.UserID) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	sub, err := h.repos.Submissions.LatestSubmission(r.Context(), payload.UserID)
+	if errors.Is(err, repositories.ErrNotFound) {
+		http.Error(w, "Result not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	tiers, err := h.TierTable(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	rates, err := h.RatingTable(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	quote, err := controllers.PriceQuote(rates, tiers, sub.Result, payload.Profile, time.Now().UTC())
+	if errors.Is(err, controllers.ErrDeclined) || errors.Is(err, controllers.ErrUnknownTier) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	quote.UserID = payload.UserID
+
+	err = h.repos.Quotes.SaveQuote(r.Context(), quote)
+	if err != nil {
+		util.Logger.Error("failed to save quote",
+			zap.String("quoteID", quote.ID),
+			zap.String("submissionID", quote.SubmissionID),
+			zap.Error(err),
+		)
+		http.Error(w, "Failed to save quote", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(quote)
+}
@@ -0,0 +1,79 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+var submissionCols = []string{"id", "user_id", "questionnaire_version", "answers", "result", "created_at"}
+
+func TestQuoteHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	result := `{"submissionId":"sub-1","totalScore":10,"policy":"Basic Cyber Insurance","decision":{"outcome":"accept"},` +
+		`"paradigms":[{"paradigm":"101","points":10,"maxPoints":10,"percentage":100}]}`
+	mock.ExpectQuery("FROM submissions WHERE user_id = ").WithArgs("quote-user", "default").
+		WillReturnRows(sqlmock.NewRows(submissionCols).AddRow("sub-1", "quote-user", 0, `{"1":"Yes"}`, result, time.Now()))
+	mock.ExpectExec("INSERT INTO quotes").
+		WithArgs(sqlmock.AnyArg(), "sub-1", "quote-user", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "default").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	body, _ := json.Marshal(map[string]any{
+		"userId":  "quote-user",
+		"profile": map[string]any{"industry": "finance", "revenueBand": "<1M", "requestedLimit": 1000000},
+	})
+	w := httptest.NewRecorder()
+	h.QuoteHandler(w, httptest.NewRequest("POST", "/quotes", bytes.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("quote: expected 201 Created, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var quote models.Quote
+	if err := json.NewDecoder(w.Body).Decode(&quote); err != nil {
+		t.Fatalf("could not decode quote: %v", err)
+	}
+	if quote.SubmissionID != "sub-1" || quote.Premium <= 0 || quote.ExpiresAt.IsZero() {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestQuoteHandlerUnknownUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	mock.ExpectQuery("FROM submissions WHERE user_id = ").WithArgs("nobody", "default").
+		WillReturnRows(sqlmock.NewRows(submissionCols))
+
+	body, _ := json.Marshal(map[string]any{
+		"userId":  "nobody",
+		"profile": map[string]any{"industry": "finance", "revenueBand": "<1M", "requestedLimit": 1000000},
+	})
+	w := httptest.NewRecorder()
+	h.QuoteHandler(w, httptest.NewRequest("POST", "/quotes", bytes.NewReader(body)))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
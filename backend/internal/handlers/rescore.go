@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// ErrInvalidCandidate is returned when a rescore request cannot be replayed.
+var ErrInvalidCandidate = errors.New("invalid rescore candidate")
+
+// RescoreRequest selects the candidate to replay stored submissions against.
+// A nil QuestionnaireVersion means the current questionnaire, 0 the live
+// questions table; nil Tiers means the current tier table.
+type RescoreRequest struct {
+	QuestionnaireVersion *int              `json:"questionnaireVersion"`
+	Tiers                *models.TierTable `json:"tiers"`
+}
+
+// BuildRescoreReport replays every applicant's latest submission against
+// the candidate described by req.
+func (h *Handler) BuildRescoreReport(ctx context.Context, req RescoreRequest) (models.RescoreReport, error) {
+	tiers, err := h.TierTable(ctx)
+	if err != nil {
+		return models.RescoreReport{}, err
+	}
+	if req.Tiers != nil {
+		if err := controllers.ValidateTierTable(*req.Tiers); err != nil {
+			return models.RescoreReport{}, fmt.Errorf("%w: %v", ErrInvalidCandidate, err)
+		}
+		tiers = *req.Tiers
+	}
+
+	var qn models.Questionnaire
+	if req.QuestionnaireVersion == nil {
+		qn, err = h.CurrentQuestionnaire(ctx)
+	} else {
+		qn, err = h.Questionnaire(ctx, *req.QuestionnaireVersion)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return models.RescoreReport{}, fmt.Errorf("%w: questionnaire version %d not found", ErrInvalidCandidate, *req.QuestionnaireVersion)
+		}
+	}
+	if err != nil {
+		return models.RescoreReport{}, err
+	}
+
+	subs, err := h.repos.Submissions.LatestSubmissions(ctx)
+	if err != nil {
+		return models.RescoreReport{}, err
+	}
+
+	report := controllers.Rescore(subs, qn.Questions, tiers)
+	report.QuestionnaireVersion = qn.Version
+	return report, nil
+}
+
+// RescoreHandler reports the impact of a candidate questionnaire or tier
+// table on stored submissions, as JSON or with ?format=csv as CSV.
+func (h *Handler) RescoreHandler(w http.ResponseWriter, r *http.Request) {
+	var req RescoreRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.BuildRescoreReport(r.Context(), req)
+	if errors.Is(err, ErrInvalidCandidate) {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="rescore.csv"`)
+		controllers.WriteRescoreCSV(w, report)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,88 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+func TestRescoreHandler(t *testing.T) {
+	for _, format := range []string{"json", "csv"} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+		h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+		mock.ExpectQuery("FROM questionnaire_versions WHERE id = ").WithArgs(2, "default").
+			WillReturnRows(sqlmock.NewRows(questionnaireCols).AddRow(2, "2026-Q2", false, time.Now(), publishedCatalog))
+		mock.ExpectQuery("SELECT DISTINCT ON \\(user_id\\)").
+			WillReturnRows(sqlmock.NewRows(submissionCols).
+				AddRow("s1", "alice", 1, `{"1":"Yes"}`, `{"totalScore":0,"policy":"Basic Cyber Insurance","decision":{"outcome":"accept"}}`, time.Now()))
+
+		body, _ := json.Marshal(map[string]any{"questionnaireVersion": 2})
+		req := httptest.NewRequest("POST", "/admin/rescore?format="+format, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		h.RescoreHandler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200 OK, got %d: %s", format, w.Code, w.Body.String())
+		}
+		if format == "csv" {
+			if !strings.Contains(w.Body.String(), "alice,s1,0,10,") {
+				t.Errorf("expected alice in the CSV report, got:\n%s", w.Body.String())
+			}
+		} else {
+			var report models.RescoreReport
+			json.NewDecoder(w.Body).Decode(&report)
+			if report.QuestionnaireVersion != 2 || len(report.Affected) != 1 || report.After.Mean != 10 {
+				t.Errorf("unexpected report: %+v", report)
+			}
+		}
+
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("%s: there were unfulfilled expectations: %s", format, err)
+		}
+		db.Close()
+	}
+}
+
+func TestRescoreHandlerRejectsInvalidTiers(t *testing.T) {
+	body, _ := json.Marshal(map[string]any{"tiers": map[string]any{"version": 3, "tiers": []any{}}})
+	h := handlers.New(repositories.From(repositories.NewMemory()))
+	w := httptest.NewRecorder()
+	h.RescoreHandler(w, httptest.NewRequest("POST", "/admin/rescore", bytes.NewReader(body)))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d", w.Code)
+	}
+}
+
+// missingQuestions is a question repository whose live catalog is gone.
+type missingQuestions struct{}
+
+func (missingQuestions) ListQuestions(ctx context.Context) ([]models.Question, error) {
+	return nil, repositories.ErrNotFound
+}
+
+func TestRescoreCurrentQuestionnaireNotFound(t *testing.T) {
+	repos := repositories.From(repositories.NewMemory())
+	repos.Questions = missingQuestions{}
+	h := handlers.New(repos)
+
+	w := httptest.NewRecorder()
+	h.RescoreHandler(w, httptest.NewRequest("POST", "/admin/rescore", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 without a current questionnaire, got %d", w.Code)
+	}
+}
@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// DefaultDraftTTL is how long a draft assessment is kept after it was last
+// saved.
+const DefaultDraftTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrSessionExpired is returned for drafts past their expiry.
+	ErrSessionExpired = errors.New("assessment expired")
+	// ErrSessionLocked is returned when changing a finalized assessment.
+	ErrSessionLocked = errors.New("assessment already finalized")
+)
+
+// SetDraftTTL sets how long drafts are kept after they were last saved.
+func (h *Handler) SetDraftTTL(ttl time.Duration) {
+	h.draftTTL = ttl
+}
+
+// sessionView fills in the computed fields of a session.
+func (h *Handler) sessionView(ctx context.Context, s models.AssessmentSession, now time.Time) (models.AssessmentSession, error) {
+	if s.Status == models.SessionDraft && now.After(s.ExpiresAt) {
+		s.Status = models.SessionExpired
+	}
+	qn, err := h.Questionnaire(ctx, s.QuestionnaireVersion)
+	if err != nil {
+		return s, err
+	}
+	s.Progress, s.Complete = controllers.SessionProgress(qn.Questions, s.Answers)
+	return s, nil
+}
+
+// openDraft loads a session that can still be changed. A revision of 0
+// accepts whatever revision is stored.
+func (h *Handler) openDraft(ctx context.Context, id string, revision int, now time.Time) (models.AssessmentSession, models.Questionnaire, error) {
+	s, err := h.repos.Sessions.GetSession(ctx, id)
+	if err != nil {
+		return s, models.Questionnaire{}, err
+	}
+	switch {
+	case !middleware.AllowedFor(ctx, s.UserID):
+		return s, models.Questionnaire{}, ErrForbidden
+	case s.Status != models.SessionDraft:
+		return s, models.Questionnaire{}, ErrSessionLocked
+	case now.After(s.ExpiresAt):
+		return s, models.Questionnaire{}, ErrSessionExpired
+	case revision > 0 && revision != s.Revision:
+		return s, models.Questionnaire{}, repositories.ErrConflict
+	}
+	qn, err := h.Questionnaire(ctx, s.QuestionnaireVersion)
+	return s, qn, err
+}
+
+// StartSession starts a draft assessment against the current questionnaire.
+func (h *Handler) StartSession(ctx context.Context, userID string) (models.AssessmentSession, error) {
+	if !middleware.AllowedFor(ctx, userID) {
+		return models.AssessmentSession{}, ErrForbidden
+	}
+	qn, err := h.CurrentQuestionnaire(ctx)
+	if err != nil {
+		return models.AssessmentSession{}, err
+	}
+	now := time.Now().UTC()
+	s := models.AssessmentSession{
+		ID:                   uuid.New().String(),
+		UserID:               userID,
+		QuestionnaireVersion: qn.Version,
+		Status:               models.SessionDraft,
+		Answers:              map[int]interface{}{},
+		Revision:             1,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+		ExpiresAt:            now.Add(h.draftTTL),
+	}
+	if err := h.repos.Sessions.CreateSession(ctx, s); err != nil {
+		return s, err
+	}
+	return h.sessionView(ctx, s, now)
+}
+
+// Session returns a session with its progress.
+func (h *Handler) Session(ctx context.Context, id string) (models.AssessmentSession, error) {
+	s, err := h.repos.Sessions.GetSession(ctx, id)
+	if err != nil {
+		return s, err
+	}
+	if !middleware.AllowedFor(ctx, s.UserID) {
+		return models.AssessmentSession{}, ErrForbidden
+	}
+	return h.sessionView(ctx, s, time.Now().UTC())
+}
+
+// SaveAnswers merges changes into a draft and extends its expiry. Every
+// changed answer is validated; if any fails, nothing is saved and the
+// failures are returned alongside ErrInvalidAnswers.
+func (h *Handler) SaveAnswers(ctx context.Context, id string, revision int, changes map[int]interface{}) (models.AssessmentSession, []models.AnswerError, error) {
+	now := time.Now().UTC()
+	s, qn, err := h.openDraft(ctx, id, revision, now)
+	if err != nil {
+		return s, nil, err
+	}
+
+	merged := controllers.MergeAnswers(s.Answers, changes)
+	if errs := controllers.ValidateDraftAnswers(changes, merged, qn.Questions); len(errs) > 0 {
+		return s, errs, ErrInvalidAnswers
+	}
+	s.Answers = merged
+	s.UpdatedAt = now
+	s.ExpiresAt = now.Add(h.draftTTL)
+
+	if s, err = h.repos.Sessions.SaveSession(ctx, s); err != nil {
+		return s, nil, err
+	}
+	s, err = h.sessionView(ctx, s, now)
+	return s, nil, err
+}
+
+// FinalizeSession scores a draft against its questionnaire version, stores
+// the submission and locks the draft. Validation failures are returned
+// alongside ErrInvalidAnswers.
+func (h *Handler) FinalizeSession(ctx context.Context, id string, revision int) (models.Result, []models.AnswerError, error) {
+	now := time.Now().UTC()
+	s, qn, err := h.openDraft(ctx, id, revision, now)
+	if err != nil {
+		return models.Result{}, nil, err
+	}
+
+	tiers, err := h.TierTable(ctx)
+	if err != nil {
+		return models.Result{}, nil, err
+	}
+	sub, errs, err := scoreSubmission(qn, tiers, s.UserID, s.Answers)
+	if err != nil {
+		return models.Result{}, errs, err
+	}
+	s.UpdatedAt = now
+
+	_, err = h.repos.Sessions.FinalizeSession(ctx, s, sub)
+	logSubmission(sub, err)
+	if err != nil {
+		return models.Result{}, nil, err
+	}
+	return sub.Result, nil, nil
+}
+
+// PurgeExpiredSessions deletes expired drafts every interval until ctx is
+// done.
+func (h *Handler) PurgeExpiredSessions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := h.repos.Sessions.PurgeExpiredSessions(ctx, time.Now().UTC())
+			if err != nil {
+				util.Logger.Error("failed to purge expired drafts", zap.Error(err))
+			} else if n > 0 {
+				util.Logger.Info("purged expired drafts", zap.Int64("count", n))
+			}
+		}
+	}
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, repositories.ErrNotFound):
+		http.Error(w, "Assessment not found", http.StatusNotFound)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, ErrSessionExpired):
+		http.Error(w, "Assessment expired", http.StatusGone)
+	case errors.Is(err, ErrSessionLocked):
+		http.Error(w, "Assessment already finalized", http.StatusConflict)
+	case errors.Is(err, repositories.ErrConflict):
+		http.Error(w, "Revision conflict, reload and retry", http.StatusPreconditionFailed)
+	default:
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+	}
+}
+
+// StartAssessmentHandler starts a draft assessment for a user.
+func (h *Handler) StartAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		UserID string `json:"userId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.UserID == "" {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	s, err := h.StartSession(r.Context(), payload.UserID)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusCreated, s.Revision, s)
+}
+
+// GetAssessmentHandler returns a draft or finalized assessment with its
+// progress per paradigm.
+func (h *Handler) GetAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	s, err := h.Session(r.Context(), mux.Vars(r)["id"])
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, s.Revision, s)
+}
+
+// SaveAnswersHandler saves some answers of a draft. A null answer clears
+// it. An If-Match header makes the save conditional on the revision.
+func (h *Handler) SaveAnswersHandler(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Answers map[int]interface{} `json:"answers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	s, errs, err := h.SaveAnswers(r.Context(), mux.Vars(r)["id"], requestRevision(r, 0), payload.Answers)
+	if errors.Is(err, ErrInvalidAnswers) {
+		writeAnswerErrors(w, errs)
+		return
+	}
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeCatalogJSON(w, http.StatusOK, s.Revision, s)
+}
+
+// FinalizeAssessmentHandler scores a draft and locks it. The response is
+// the result, as from /submit.
+func (h *Handler) FinalizeAssessmentHandler(w http.ResponseWriter, r *http.Request) {
+	result, errs, err := h.FinalizeSession(r.Context(), mux.Vars(r)["id"], requestRevision(r, 0))
+	if errors.Is(err, ErrInvalidAnswers) {
+		writeAnswerErrors(w, errs)
+		return
+	}
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(explained(r, result))
+}
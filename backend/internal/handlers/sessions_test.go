@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+func sessionStore() *repositories.Memory {
+	store := repositories.NewMemory()
+	store.SetQuestions([]models.Question{
+		{ID: 1, Paradigm: "1", Text: "Do you use MFA?", Selector: "radio", Options: []string{"Yes", "No"}, Weight: 10, Required: true},
+		{ID: 2, Paradigm: "2", Text: "Which clouds?", Selector: "checkbox", Options: []string{"AWS", "GCP"}, Weight: 10, Required: true},
+	})
+	return store
+}
+
+func decodeSession(t *testing.T, w *httptest.ResponseRecorder) models.AssessmentSession {
+	t.Helper()
+	var s models.AssessmentSession
+	if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+		t.Fatalf("failed to decode session: %v", err)
+	}
+	return s
+}
+
+func TestAssessmentSessionLifecycle(t *testing.T) {
+	store := sessionStore()
+	h := handlers.New(repositories.From(store))
+
+	w := httptest.NewRecorder()
+	h.StartAssessmentHandler(w, catalogRequest("POST", "/assessments", nil, map[string]string{"userId": "12"}))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	s := decodeSession(t, w)
+	vars := map[string]string{"id": s.ID}
+	if s.Status != models.SessionDraft || len(s.Progress) != 2 || s.Complete {
+		t.Fatalf("unexpected new session: %+v", s)
+	}
+
+	save := func(answers map[string]any) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		h.SaveAnswersHandler(w, catalogRequest("PATCH", "/assessments/"+s.ID+"/answers", vars, map[string]any{"answers": answers}))
+		return w
+	}
+
+	if w := save(map[string]any{"1": "Maybe"}); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected an invalid answer to be rejected, got %d", w.Code)
+	}
+	if w := save(map[string]any{"1": "Yes"}); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.FinalizeAssessmentHandler(w, catalogRequest("POST", "/assessments/"+s.ID+"/finalize", vars, nil))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected finalizing without required answers to fail, got %d", w.Code)
+	}
+
+	w = save(map[string]any{"2": []string{"AWS"}})
+	s = decodeSession(t, w)
+	if !s.Complete || s.Revision != 3 || s.Progress[1].Answered != 1 {
+		t.Fatalf("expected a complete draft at revision 3, got %+v", s)
+	}
+
+	w = httptest.NewRecorder()
+	h.FinalizeAssessmentHandler(w, catalogRequest("POST", "/assessments/"+s.ID+"/finalize", vars, nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result models.Result
+	json.NewDecoder(w.Body).Decode(&result)
+	if result.TotalScore != 15 {
+		t.Errorf("expected a score of 15, got %+v", result)
+	}
+
+	if w := save(map[string]any{"1": "No"}); w.Code != http.StatusConflict {
+		t.Errorf("expected a finalized assessment to be locked, got %d", w.Code)
+	}
+	latest, err := store.LatestSubmission(context.Background(), "12")
+	if err != nil || latest.ID != result.SubmissionID {
+		t.Errorf("expected the finalized submission to be stored, got %+v, %v", latest, err)
+	}
+
+	w = httptest.NewRecorder()
+	h.GetAssessmentHandler(w, catalogRequest("GET", "/assessments/"+s.ID, vars, nil))
+	if s := decodeSession(t, w); s.Status != models.SessionFinalized || s.SubmissionID != result.SubmissionID {
+		t.Errorf("expected the session to be finalized, got %+v", s)
+	}
+}
+
+func TestAssessmentSessionRevisionAndExpiry(t *testing.T) {
+	store := sessionStore()
+	h := handlers.New(repositories.From(store))
+	ctx := context.Background()
+
+	s, err := h.StartSession(ctx, "12")
+	if err != nil {
+		t.Fatalf("failed to start session: %v", err)
+	}
+	req := catalogRequest("PATCH", "/assessments/"+s.ID+"/answers", map[string]string{"id": s.ID},
+		map[string]any{"answers": map[string]any{"1": "Yes"}})
+	req.Header.Set("If-Match", `"2"`)
+	w := httptest.NewRecorder()
+	h.SaveAnswersHandler(w, req)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected a stale revision to be rejected, got %d", w.Code)
+	}
+
+	past := time.Now().UTC().Add(-time.Hour)
+	store.CreateSession(ctx, models.AssessmentSession{ID: "old", UserID: "12", Status: models.SessionDraft,
+		Revision: 1, CreatedAt: past.Add(-time.Hour), UpdatedAt: past.Add(-time.Hour), ExpiresAt: past})
+
+	vars := map[string]string{"id": "old"}
+	w = httptest.NewRecorder()
+	h.GetAssessmentHandler(w, catalogRequest("GET", "/assessments/old", vars, nil))
+	if s := decodeSession(t, w); s.Status != models.SessionExpired {
+		t.Errorf("expected the draft to report expired, got %+v", s)
+	}
+	w = httptest.NewRecorder()
+	h.SaveAnswersHandler(w, catalogRequest("PATCH", "/assessments/old/answers", vars, map[string]any{"answers": map[string]any{"1": "Yes"}}))
+	if w.Code != http.StatusGone {
+		t.Errorf("expected 410 for an expired draft, got %d", w.Code)
+	}
+
+	if n, _ := store.PurgeExpiredSessions(ctx, time.Now().UTC()); n != 1 {
+		t.Errorf("expected the expired draft to be purged, got %d", n)
+	}
+	if _, err := store.GetSession(ctx, s.ID); err != nil {
+		t.Errorf("expected the open draft to be kept, got %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/controllers"
+)
+
+// AssessmentHistoryHandler lists a user's assessments, oldest first, with
+// the change since the previous one.
+func (h *Handler) AssessmentHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.repos.Submissions.UserSubmissions(r.Context(), mux.Vars(r)["userID"])
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(controllers.AssessmentHistory(subs))
+}
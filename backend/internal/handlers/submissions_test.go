@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+func TestResultHandlerReadsLatestSubmission(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	result := `{"totalScore":15,"policy":"Basic Cyber Insurance","trace":[{"questionId":1,"points":10}]}`
+	mock.ExpectQuery("FROM submissions WHERE user_id = (.+) ORDER BY created_at DESC LIMIT 1").WithArgs("12", "default").
+		WillReturnRows(sqlmock.NewRows(submissionCols).AddRow("sub-1", "12", 0, `{"1":"Yes"}`, result, time.Now()))
+	mock.ExpectQuery("FROM submissions WHERE user_id = ").WithArgs("13", "default").
+		WillReturnRows(sqlmock.NewRows(submissionCols))
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/result/12", nil), map[string]string{"userID": "12"})
+	w := httptest.NewRecorder()
+	h.ResultHandler(w, req)
+
+	var res models.Result
+	if err := json.NewDecoder(w.Body).Decode(&res); err != nil {
+		t.Fatalf("could not decode result: %v", err)
+	}
+	if res.TotalScore != 15 || res.Trace != nil {
+		t.Errorf("unexpected result: %+v", res)
+	}
+
+	req = mux.SetURLVars(httptest.NewRequest("GET", "/result/13", nil), map[string]string{"userID": "13"})
+	w = httptest.NewRecorder()
+	h.ResultHandler(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for a user without submissions, got %d", w.Code)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAssessmentHistoryHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+	h := handlers.New(repositories.From(repositories.NewPostgres(db)))
+
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectQuery("FROM submissions WHERE user_id = (.+) ORDER BY created_at$").WithArgs("12", "default").
+		WillReturnRows(sqlmock.NewRows(submissionCols).
+			AddRow("sub-1", "12", 1, `{"1":"No"}`, `{"totalScore":5,"policy":"Basic Cyber Insurance"}`, first).
+			AddRow("sub-2", "12", 2, `{"1":"Yes"}`, `{"totalScore":25,"policy":"Standard Cyber Insurance"}`, first.AddDate(0, 1, 0)))
+
+	req := mux.SetURLVars(httptest.NewRequest("GET", "/users/12/assessments", nil), map[string]string{"userID": "12"})
+	w := httptest.NewRecorder()
+	h.AssessmentHistoryHandler(w, req)
+
+	var history []models.Assessment
+	if err := json.NewDecoder(w.Body).Decode(&history); err != nil {
+		t.Fatalf("could not decode history: %v", err)
+	}
+	if len(history) != 2 || history[1].Delta == nil || history[1].Delta.Score != 20 {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if history[1].QuestionnaireVersion != 2 {
+		t.Errorf("expected the questionnaire version to be kept, got %d", history[1].QuestionnaireVersion)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
+	"cyber-go/internal/util"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidTenant is returned when a tenant to create fails validation.
+var ErrInvalidTenant = errors.New("invalid tenant")
+
+// DefaultConfigTTL is how long tier and rating tables are used before they
+// are read from the database again.
+const DefaultConfigTTL = time.Minute
+
+// cached is the table of one tenant and when it was loaded.
+type cached[T any] struct {
+	table  T
+	loaded time.Time
+}
+
+// configCache keeps the tier and rating tables of each tenant.
+type configCache struct {
+	sync.RWMutex
+	tiers map[string]cached[models.TierTable]
+	rates map[string]cached[models.RatingTable]
+}
+
+func newConfigCache() *configCache {
+	return &configCache{tiers: map[string]cached[models.TierTable]{}, rates: map[string]cached[models.RatingTable]{}}
+}
+
+// SetConfigTTL sets how long tier and rating tables are used before they are
+// read again, so that tables stored since, as by catalog import, take effect
+// without a restart.
+func (h *Handler) SetConfigTTL(ttl time.Duration) {
+	h.configTTL = ttl
+}
+
+// cachedTable returns the table of tenant id in entries, loading it again
+// with load once it is older than ttl. The default tenant starts from the
+// table loaded at startup. If loading again fails, the previous table is
+// kept and the failure logged, so a bad edit does not stop scoring.
+func cachedTable[T any](c *configCache, entries map[string]cached[T], id string, ttl time.Duration, startup func() T, load func() (T, error)) (T, error) {
+	now := time.Now()
+	c.RLock()
+	entry, ok := entries[id]
+	c.RUnlock()
+	switch {
+	case ok && now.Sub(entry.loaded) < ttl:
+		return entry.table, nil
+	case !ok && id == tenant.Default:
+		entry = cached[T]{table: startup(), loaded: now}
+	default:
+		table, err := load()
+		if err != nil && !ok {
+			return table, err
+		}
+		if err != nil {
+			util.Logger.Warn("failed to reload tenant configuration, keeping the previous one",
+				zap.String("tenant", id), zap.Error(err))
+			table = entry.table
+		}
+		entry = cached[T]{table: table, loaded: now}
+	}
+
+	c.Lock()
+	entries[id] = entry
+	c.Unlock()
+	return entry.table, nil
+}
+
+// TierTable returns the tier table the tenant of ctx scores with, read from
+// the database at most once per SetConfigTTL. Tenants without a stored table
+// use controllers.DefaultTierTable.
+func (h *Handler) TierTable(ctx context.Context) (models.TierTable, error) {
+	id := tenant.FromContext(ctx)
+	return cachedTable(h.config, h.config.tiers, id, h.configTTL, controllers.CurrentTierTable, func() (models.TierTable, error) {
+		t, err := h.repos.ScoringConfig.CurrentTiers(ctx)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return controllers.DefaultTierTable, nil
+		}
+		if err != nil {
+			return t, err
+		}
+		if err := controllers.ValidateTierTable(t); err != nil {
+			return t, fmt.Errorf("tenant %s: invalid policy tiers version %d: %w", id, t.Version, err)
+		}
+		return t, nil
+	})
+}
+
+// RatingTable returns the rating table the tenant of ctx prices quotes
+// with, read like its tier table (see TierTable). Tenants without a stored
+// table use controllers.DefaultRatingTable.
+func (h *Handler) RatingTable(ctx context.Context) (models.RatingTable, error) {
+	id := tenant.FromContext(ctx)
+	return cachedTable(h.config, h.config.rates, id, h.configTTL, controllers.CurrentRatingTable, func() (models.RatingTable, error) {
+		t, err := h.repos.ScoringConfig.CurrentRatingTable(ctx)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return controllers.DefaultRatingTable, nil
+		}
+		if err != nil {
+			return t, err
+		}
+		if err := controllers.ValidateRatingTable(t); err != nil {
+			return t, fmt.Errorf("tenant %s: invalid rating table version %d: %w", id, t.Version, err)
+		}
+		return t, nil
+	})
+}
+
+// CreateTenant validates and stores a new tenant. Validation failures wrap
+// ErrInvalidTenant; a taken id gives repositories.ErrConflict.
+func (h *Handler) CreateTenant(ctx context.Context, t models.Tenant) (models.Tenant, error) {
+	if err := controllers.ValidateTenant(t); err != nil {
+		return t, fmt.Errorf("%w: %v", ErrInvalidTenant, err)
+	}
+	return h.repos.Tenants.CreateTenant(ctx, t)
+}
+
+// Tenants lists every tenant.
+func (h *Handler) Tenants(ctx context.Context) ([]models.Tenant, error) {
+	return h.repos.Tenants.ListTenants(ctx)
+}
+
+// AdminTenantsHandler lists every tenant.
+func (h *Handler) AdminTenantsHandler(w http.ResponseWriter, r *http.Request) {
+	tenants, err := h.Tenants(r.Context())
+	if err != nil {
+		http.Error(w, "Database query error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tenants)
+}
+
+// CreateTenantHandler creates a tenant from {"id", "name"}.
+func (h *Handler) CreateTenantHandler(w http.ResponseWriter, r *http.Request) {
+	var t models.Tenant
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, "Invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	t, err := h.CreateTenant(r.Context(), t)
+	switch {
+	case errors.Is(err, ErrInvalidTenant):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, repositories.ErrConflict):
+		http.Error(w, "Tenant already exists", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
@@ -0,0 +1,147 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/handlers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
+)
+
+func tenantRouter(t *testing.T) (*mux.Router, *handlers.Handler) {
+	t.Helper()
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	if _, err := h.CreateTenant(context.Background(), models.Tenant{ID: "acme", Name: "Acme Brokers"}); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	acmeTiers := &models.TierTable{Version: 1, Tiers: []models.PolicyTier{{Name: "Acme Basic"}, {Name: "Acme Plus", MinScore: 15}}}
+	store.ImportCatalog(tenant.WithID(context.Background(), "acme"), models.CatalogPlan{Tiers: acmeTiers})
+
+	r := mux.NewRouter()
+	r.Use(middleware.Tenant(store, middleware.TenantFromTokens(map[string]string{"acme-token": "acme"}), middleware.TenantFromHeader))
+	r.HandleFunc("/submit", h.SubmitHandler).Methods("POST")
+	r.HandleFunc("/result/{userID}", h.ResultHandler).Methods("GET")
+	r.HandleFunc("/policies", h.GetPoliciesHandler).Methods("GET")
+	return r, h
+}
+
+func tenantDo(r http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestTenantsAreIsolated(t *testing.T) {
+	r, _ := tenantRouter(t)
+	acme := map[string]string{"X-Tenant-ID": "acme"}
+
+	w := tenantDo(r, "POST", "/submit", `{"userId": "12", "answers": {"1": "Yes", "2": "Yes"}}`, acme)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result models.Result
+	json.NewDecoder(w.Body).Decode(&result)
+	if result.Policy != "Acme Plus" {
+		t.Errorf("expected the tenant's tiers to be used, got %q", result.Policy)
+	}
+
+	if w := tenantDo(r, "GET", "/result/12", "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected the default tenant not to see the result, got %d", w.Code)
+	}
+	if w := tenantDo(r, "GET", "/result/12", "", map[string]string{"Authorization": "Bearer acme-token"}); w.Code != http.StatusOK {
+		t.Errorf("expected the tenant token to resolve acme, got %d", w.Code)
+	}
+
+	var tiers models.TierTable
+	json.NewDecoder(tenantDo(r, "GET", "/policies", "", acme).Body).Decode(&tiers)
+	if len(tiers.Tiers) != 2 || tiers.Tiers[0].Name != "Acme Basic" {
+		t.Errorf("expected the tenant's tier table, got %+v", tiers)
+	}
+}
+
+func TestImportedTiersTakeEffectAfterTTL(t *testing.T) {
+	store := catalogStore()
+	h := handlers.New(repositories.From(store))
+	if _, err := h.CreateTenant(context.Background(), models.Tenant{ID: "acme", Name: "Acme Brokers"}); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	acme := tenant.WithID(context.Background(), "acme")
+	importTiers := func(version int, first string) {
+		table := &models.TierTable{Version: version, Tiers: []models.PolicyTier{{Name: first}}}
+		if err := store.ImportCatalog(acme, models.CatalogPlan{Tiers: table}); err != nil {
+			t.Fatalf("failed to import tiers: %v", err)
+		}
+	}
+	tierName := func() string {
+		tiers, err := h.TierTable(acme)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return tiers.Tiers[0].Name
+	}
+
+	importTiers(1, "Acme Basic")
+	if got := tierName(); got != "Acme Basic" {
+		t.Fatalf("expected the stored tiers, got %q", got)
+	}
+	importTiers(2, "Acme Core")
+	if got := tierName(); got != "Acme Basic" {
+		t.Errorf("expected the cached tiers within the TTL, got %q", got)
+	}
+
+	h.SetConfigTTL(0)
+	if got := tierName(); got != "Acme Core" {
+		t.Errorf("expected the imported tiers once the TTL passed, got %q", got)
+	}
+	importTiers(3, "")
+	if got := tierName(); got != "Acme Core" {
+		t.Errorf("expected an invalid table to keep the previous tiers, got %q", got)
+	}
+}
+
+func TestTenantResolution(t *testing.T) {
+	r, _ := tenantRouter(t)
+
+	if w := tenantDo(r, "GET", "/policies", "", map[string]string{"X-Tenant-ID": "nobody"}); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown tenant, got %d", w.Code)
+	}
+	conflicting := map[string]string{"Authorization": "Bearer acme-token", "X-Tenant-ID": "default"}
+	if w := tenantDo(r, "GET", "/policies", "", conflicting); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 when the header contradicts the token, got %d", w.Code)
+	}
+}
+
+func TestCreateTenantHandler(t *testing.T) {
+	_, h := tenantRouter(t)
+	for _, tc := range []struct {
+		body string
+		want int
+	}{
+		{`{"id": "carrier-2", "name": "Carrier 2"}`, http.StatusCreated},
+		{`{"id": "acme", "name": "Acme again"}`, http.StatusConflict},
+		{`{"id": "Bad Id", "name": "Bad"}`, http.StatusUnprocessableEntity},
+	} {
+		w := httptest.NewRecorder()
+		h.CreateTenantHandler(w, httptest.NewRequest("POST", "/admin/tenants", strings.NewReader(tc.body)))
+		if w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d: %s", tc.body, tc.want, w.Code, w.Body.String())
+		}
+	}
+	if tenants, _ := h.Tenants(context.Background()); len(tenants) != 3 {
+		t.Errorf("expected three tenants, got %+v", tenants)
+	}
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"cyber-go/internal/tenant"
+)
+
+type adminKey struct{}
+
+// AdminToken marks requests carrying "Authorization: Bearer <token>" as
+// admin requests. It never rejects a request; use RequireAdmin for that. An
+// empty token disables admin access.
+func AdminToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok && token != "" && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+				r = r.WithContext(context.WithValue(r.Context(), adminKey{}, true))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// IsAdmin reports whether the request was authenticated as an admin, with
+// the admin token or as a principal with the admin role.
+func IsAdmin(ctx context.Context) bool {
+	if admin, _ := ctx.Value(adminKey{}).(bool); admin {
+		return true
+	}
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Can(PermAdmin)
+}
+
+// IsGlobalAdmin reports whether the request may administer any tenant,
+// such as the tenants themselves and their catalogs: requests with the admin
+// token, and admins of tenant.Default. Admins of other tenants only
+// administer their own tenant.
+func IsGlobalAdmin(ctx context.Context) bool {
+	if admin, _ := ctx.Value(adminKey{}).(bool); admin {
+		return true
+	}
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Can(PermAdmin) && (p.Tenant == "" || p.Tenant == tenant.Default)
+}
+
+// RequireGlobalAdmin rejects requests that were not authenticated as a
+// global admin; see IsGlobalAdmin.
+func RequireGlobalAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsGlobalAdmin(r.Context()) {
+			deny(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireAdmin rejects requests that were not authenticated as an admin.
+func RequireAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !IsAdmin(r.Context()) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/models"
+	"cyber-go/internal/observability"
+	"cyber-go/internal/repositories"
+)
+
+// APIKeyHeader carries the API key of a broker system. Keys are also
+// accepted as bearer tokens.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyOf returns the API key a request was sent with. ok is false for
+// requests that carry none, so other bearer tokens pass through.
+func apiKeyOf(r *http.Request) (key string, ok bool) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return key, true
+	}
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && strings.HasPrefix(bearer, controllers.APIKeyPrefix) {
+		return bearer, true
+	}
+	return "", false
+}
+
+// apiKeyKey holds the API key a request was authenticated with.
+type apiKeyKey struct{}
+
+// APIKeys authenticates requests sent with an API key as a principal with
+// the key's scopes, scoped to the key's tenant (see TenantFromPrincipal).
+// Unknown, revoked and expired keys are rejected with 401. Every other
+// request made with a key is counted, by key id and response status, in
+// the api_key_requests_total metric. Daily quotas are checked by
+// APIKeyQuota.
+func APIKeys(keys repositories.APIKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			raw, ok := apiKeyOf(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			invalid := func() {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				http.Error(w, "Invalid API key", http.StatusUnauthorized)
+			}
+			id, _, ok := controllers.ParseAPIKey(raw)
+			if !ok {
+				invalid()
+				return
+			}
+
+			k, err := keys.GetAPIKey(r.Context(), id)
+			if errors.Is(err, repositories.ErrNotFound) {
+				invalid()
+				return
+			}
+			if err != nil {
+				http.Error(w, "Database query error", http.StatusInternalServerError)
+				return
+			}
+			if subtle.ConstantTimeCompare([]byte(controllers.HashAPIKey(raw)), []byte(k.SecretHash)) != 1 || !k.Active(time.Now()) {
+				invalid()
+				return
+			}
+
+			ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			defer func() {
+				observability.CountAPIKeyRequest(k.ID, http.StatusText(ww.statusCode))
+			}()
+
+			p := Principal{Subject: "apikey:" + k.ID, Tenant: k.Tenant, APIKey: k.ID}
+			for _, scope := range k.Scopes {
+				p.Scopes = append(p.Scopes, Permission(scope))
+			}
+			ctx := context.WithValue(WithPrincipal(enforce(r.Context()), p), apiKeyKey{}, k)
+			next.ServeHTTP(ww, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIKeyQuota counts the requests made with each API key against its daily
+// quota, and rejects those over it with 429 until the next UTC day.
+// Install it after RateLimit, so requests the rate limiter rejects do not
+// use up the quota.
+func APIKeyQuota(keys repositories.APIKeyRepository) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k, ok := r.Context().Value(apiKeyKey{}).(models.APIKey)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			now := time.Now()
+			used, err := keys.RecordAPIKeyUse(r.Context(), k.ID, now)
+			if err != nil {
+				http.Error(w, "Database query error", http.StatusInternalServerError)
+				return
+			}
+			if k.DailyQuota > 0 && used > k.DailyQuota {
+				tomorrow := now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(tomorrow.Sub(now).Seconds()))))
+				http.Error(w, "API key quota exceeded", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"cyber-go/internal/controllers"
+	"cyber-go/internal/middleware"
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+)
+
+// storeKey stores an API key with the given settings and returns it.
+func storeKey(t *testing.T, store *repositories.Memory, k models.APIKey) string {
+	t.Helper()
+	id, key, err := controllers.NewAPIKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	k.ID, k.SecretHash, k.CreatedAt = id, controllers.HashAPIKey(key), time.Now()
+	if err := store.CreateAPIKey(context.Background(), k); err != nil {
+		t.Fatalf("failed to store key: %v", err)
+	}
+	return key
+}
+
+func TestAPIKeysAuthenticateBrokers(t *testing.T) {
+	store := repositories.NewMemory()
+	past := time.Now().Add(-time.Minute)
+	submitter := storeKey(t, store, models.APIKey{Name: "broker", Scopes: []string{"submit"}})
+	limited := storeKey(t, store, models.APIKey{Name: "limited", Scopes: []string{"read:questions"}, DailyQuota: 1})
+	expired := storeKey(t, store, models.APIKey{Name: "expired", Scopes: []string{"submit"}, ExpiresAt: &past})
+	revoked := storeKey(t, store, models.APIKey{Name: "revoked", Scopes: []string{"submit"}})
+	revokedID, _, _ := controllers.ParseAPIKey(revoked)
+	store.RevokeAPIKey(context.Background(), revokedID, past)
+	forged := submitter[:len(submitter)-4] + "AAAA"
+
+	var seen middleware.Principal
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = middleware.PrincipalFrom(r.Context())
+	})
+	chain := func(perm middleware.Permission) http.Handler {
+		return middleware.APIKeys(store)(middleware.APIKeyQuota(store)(middleware.Require(perm)(ok)))
+	}
+	do := func(perm middleware.Permission, header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		chain(perm).ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do(middleware.PermSubmit, middleware.APIKeyHeader, submitter); w.Code != http.StatusOK {
+		t.Errorf("expected a valid key to pass, got %d", w.Code)
+	}
+	if seen.APIKey == "" || seen.Tenant != "default" {
+		t.Errorf("expected the key's principal and tenant, got %+v", seen)
+	}
+	if w := do(middleware.PermSubmit, "Authorization", "Bearer "+submitter); w.Code != http.StatusOK {
+		t.Errorf("expected keys as bearer tokens to pass, got %d", w.Code)
+	}
+	if w := do(middleware.PermReadResults, middleware.APIKeyHeader, submitter); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403 outside the key's scopes, got %d", w.Code)
+	}
+	for name, key := range map[string]string{"expired": expired, "revoked": revoked, "forged": forged, "malformed": "not-a-key"} {
+		if w := do(middleware.PermSubmit, middleware.APIKeyHeader, key); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected 401 for a %s key, got %d", name, w.Code)
+		}
+	}
+	if w := do(middleware.PermSubmit, "Authorization", "Bearer admin-token"); w.Code != http.StatusOK {
+		t.Errorf("expected other bearer tokens to pass through, got %d", w.Code)
+	}
+
+	if w := do(middleware.PermReadQuestions, middleware.APIKeyHeader, limited); w.Code != http.StatusOK {
+		t.Errorf("expected the first request within quota to pass, got %d", w.Code)
+	}
+	w := do(middleware.PermReadQuestions, middleware.APIKeyHeader, limited)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429 over the daily quota, got %d", w.Code)
+	}
+	if secs, err := strconv.Atoi(w.Header().Get("Retry-After")); err != nil || secs <= 0 || secs > 24*60*60 {
+		t.Errorf("expected Retry-After until the next day, got %q", w.Header().Get("Retry-After"))
+	}
+}
+
+func TestRateLimitedRequestsDoNotUseQuota(t *testing.T) {
+	store := repositories.NewMemory()
+	key := storeKey(t, store, models.APIKey{Name: "broker", Scopes: []string{"read:questions"}, DailyQuota: 2})
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	limited := middleware.APIKeys(store)(middleware.RateLimit(middleware.NewMemoryRateLimitStore(),
+		map[string]middleware.Limit{middleware.DefaultRoute: {Rate: 1.0 / 60, Burst: 1}})(middleware.APIKeyQuota(store)(ok)))
+	unlimited := middleware.APIKeys(store)(middleware.APIKeyQuota(store)(ok))
+	do := func(h http.Handler) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set(middleware.APIKeyHeader, key)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := do(limited); code != http.StatusOK {
+		t.Fatalf("expected the first request to pass, got %d", code)
+	}
+	if code := do(limited); code != http.StatusTooManyRequests {
+		t.Fatalf("expected the rate limiter to reject the second request, got %d", code)
+	}
+	if code := do(unlimited); code != http.StatusOK {
+		t.Errorf("expected the throttled request not to count against the quota, got %d", code)
+	}
+	if code := do(unlimited); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 once the quota is used up, got %d", code)
+	}
+}
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/gorilla/mux"
+)
+
+// Role is what a principal is to the service.
+type Role string
+
+const (
+	RoleApplicant   Role = "applicant"
+	RoleUnderwriter Role = "underwriter"
+	RoleAdmin       Role = "admin"
+)
+
+// Permission is an action a route may require.
+type Permission string
+
+const (
+	PermReadQuestions Permission = "read:questions"
+	PermSubmit        Permission = "submit"
+	// PermReadResults reads the principal's own results and assessments.
+	PermReadResults Permission = "read:results"
+	// PermReadAllResults reads and acts on any applicant's results.
+	PermReadAllResults Permission = "read:all-results"
+	PermQuote          Permission = "quote"
+	PermAdmin          Permission = "admin"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleApplicant:   {PermReadQuestions, PermSubmit, PermReadResults, PermQuote},
+	RoleUnderwriter: {PermReadQuestions, PermSubmit, PermReadResults, PermReadAllResults, PermQuote},
+	RoleAdmin:       {PermReadQuestions, PermSubmit, PermReadResults, PermReadAllResults, PermQuote, PermAdmin},
+}
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject is the applicant's user id for applicants.
+	Subject string
+	Issuer  string
+	Tenant  string
+	Roles   []Role
+	// APIKey is the id of the API key a broker system authenticated
+	// with. Brokers act for every applicant of their tenant, within
+	// the key's Scopes.
+	APIKey string
+	Scopes []Permission
+}
+
+// Can reports whether any of the principal's roles or scopes grants perm.
+func (p Principal) Can(perm Permission) bool {
+	if slices.Contains(p.Scopes, perm) {
+		return true
+	}
+	for _, role := range p.Roles {
+		if slices.Contains(rolePermissions[role], perm) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+type enforcedKey struct{}
+
+// WithPrincipal returns a copy of ctx authenticated as p.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal the request was authenticated as.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// enforce marks ctx as served with authentication on. Without it, as when
+// the server runs with AUTH_DISABLED, every request is allowed.
+func enforce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, enforcedKey{}, true)
+}
+
+func enforced(ctx context.Context) bool {
+	on, _ := ctx.Value(enforcedKey{}).(bool)
+	return on
+}
+
+// Allowed reports whether the request may do what perm guards. Admin-token
+// requests may do anything.
+func Allowed(ctx context.Context, perm Permission) bool {
+	if !enforced(ctx) || IsAdmin(ctx) {
+		return true
+	}
+	p, ok := PrincipalFrom(ctx)
+	return ok && p.Can(perm)
+}
+
+// AllowedFor reports whether the request may read or act on the results of
+// userID: principals with PermReadAllResults and API keys may for anyone,
+// applicants only for themselves. What they may do is up to their
+// permissions.
+func AllowedFor(ctx context.Context, userID string) bool {
+	if Allowed(ctx, PermReadAllResults) {
+		return true
+	}
+	p, ok := PrincipalFrom(ctx)
+	return ok && (p.APIKey != "" || p.Can(PermReadResults) && p.Subject == userID)
+}
+
+// deny answers 401 to unauthenticated requests and 403 to the others.
+func deny(w http.ResponseWriter, r *http.Request) {
+	if _, ok := PrincipalFrom(r.Context()); !ok {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	http.Error(w, "Forbidden", http.StatusForbidden)
+}
+
+// Require rejects requests not allowed perm.
+func Require(perm Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Allowed(r.Context(), perm) {
+				deny(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUser rejects requests not allowed PermReadResults for the user
+// named by the route variable userVar, as AllowedFor decides.
+func RequireUser(userVar string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !Allowed(r.Context(), PermReadResults) || !AllowedFor(r.Context(), mux.Vars(r)[userVar]) {
+				deny(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
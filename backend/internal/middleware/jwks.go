@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// KeySet holds the public keys of a JSON Web Key Set by key id.
+type KeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS reads the RSA and EC signing keys of a JWKS document. Keys for
+// encryption and of other types are skipped.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	set := &KeySet{keys: map[string]crypto.PublicKey{}}
+	for i, k := range doc.Keys {
+		if k.Use == "enc" {
+			continue
+		}
+		var key crypto.PublicKey
+		var err error
+		switch k.Kty {
+		case "RSA":
+			key, err = rsaKey(k)
+		case "EC":
+			key, err = ecKey(k)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %d (%s): %w", i, k.Kid, err)
+		}
+		set.keys[k.Kid] = key
+	}
+	if len(set.keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return set, nil
+}
+
+func rsaKey(k jwk) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid n: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil || len(e) == 0 || len(e) > 4 {
+		return nil, errors.New("invalid e")
+	}
+	key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	if key.N.BitLen() < 2048 {
+		return nil, fmt.Errorf("%d-bit RSA key is too short", key.N.BitLen())
+	}
+	return key, nil
+}
+
+func ecKey(k jwk) (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+	x, errX := base64.RawURLEncoding.DecodeString(k.X)
+	y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+	size := (curve.Params().BitSize + 7) / 8
+	if errX != nil || errY != nil || len(x) != size || len(y) != size {
+		return nil, errors.New("invalid coordinates")
+	}
+	point := append([]byte{4}, append(x, y...)...)
+	return ecdsa.ParseUncompressedPublicKey(curve, point)
+}
+
+// keySource returns the key with the given id, or every key if kid is "".
+type keySource interface {
+	publicKeys(ctx context.Context, kid string) ([]crypto.PublicKey, error)
+}
+
+func (s *KeySet) lookup(kid string) []crypto.PublicKey {
+	if kid != "" {
+		if key, ok := s.keys[kid]; ok {
+			return []crypto.PublicKey{key}
+		}
+		return nil
+	}
+	all := make([]crypto.PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		all = append(all, key)
+	}
+	return all
+}
+
+func (s *KeySet) publicKeys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	return s.lookup(kid), nil
+}
+
+// LoadJWKSFile reads a key set from a local file.
+func LoadJWKSFile(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// jwksRefreshInterval limits how often an unknown key id makes a remote key
+// set be fetched again, so forged key ids cannot flood the issuer.
+const jwksRefreshInterval = time.Minute
+
+// remoteKeySet fetches a key set from a URL on first use, and again when a
+// token names a key it does not hold, which is how issuers rotate keys.
+// Failed fetches count towards jwksRefreshInterval too, and one fetch runs
+// at a time without holding up requests that need no fetch.
+type remoteKeySet struct {
+	url    string
+	client *http.Client
+
+	mu  sync.Mutex
+	set *KeySet
+	// attempted is when the last fetch started, and err how it failed.
+	attempted time.Time
+	err       error
+	// fetching is closed when the running fetch is done.
+	fetching chan struct{}
+}
+
+func newRemoteKeySet(url string) *remoteKeySet {
+	return &remoteKeySet{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *remoteKeySet) publicKeys(ctx context.Context, kid string) ([]crypto.PublicKey, error) {
+	s.mu.Lock()
+	for {
+		if s.set != nil {
+			if keys := s.set.lookup(kid); len(keys) > 0 {
+				s.mu.Unlock()
+				return keys, nil
+			}
+		}
+		if s.fetching == nil {
+			break
+		}
+		// Another request is fetching; its result may hold the key.
+		done := s.fetching
+		s.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		s.mu.Lock()
+		if time.Since(s.attempted) < jwksRefreshInterval {
+			return s.current(kid)
+		}
+	}
+	if time.Since(s.attempted) < jwksRefreshInterval {
+		return s.current(kid)
+	}
+
+	done := make(chan struct{})
+	s.fetching, s.attempted = done, time.Now()
+	s.mu.Unlock()
+	// The fetch is shared, so it must not end with the request starting it.
+	set, err := s.fetch(context.WithoutCancel(ctx))
+
+	s.mu.Lock()
+	if err == nil {
+		s.set = set
+	}
+	s.err, s.fetching = err, nil
+	close(done)
+	return s.current(kid)
+}
+
+// current looks kid up in the last key set fetched and unlocks s.mu.
+func (s *remoteKeySet) current(kid string) ([]crypto.PublicKey, error) {
+	defer s.mu.Unlock()
+	if s.set == nil {
+		return nil, s.err
+	}
+	return s.set.lookup(kid), nil
+}
+
+func (s *remoteKeySet) fetch(ctx context.Context) (*KeySet, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", s.url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
@@ -0,0 +1,305 @@
+package middleware
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is returned for bearer tokens that fail verification.
+var ErrInvalidToken = errors.New("invalid token")
+
+// clockSkew is how far exp and nbf may be off the local clock.
+const clockSkew = time.Minute
+
+// IssuerConfig is an OpenID Connect provider whose tokens are accepted.
+type IssuerConfig struct {
+	// Issuer must equal the iss claim.
+	Issuer string `json:"issuer"`
+	// Audience, if set, must be among the aud claim.
+	Audience string `json:"audience"`
+	// Exactly one of JWKSURL and JWKSFile locates the signing keys.
+	JWKSURL  string `json:"jwksUrl"`
+	JWKSFile string `json:"jwksFile"`
+	// RolesClaim is the dotted path of the claim listing roles, such as
+	// "realm_access.roles"; "roles" by default.
+	RolesClaim string `json:"rolesClaim"`
+	// RoleMap maps claim values to roles. Values naming a role, such as
+	// "underwriter", map to it without an entry.
+	RoleMap map[string]Role `json:"roleMap"`
+	// SubjectClaim holds the applicant's user id; "sub" by default.
+	SubjectClaim string `json:"subjectClaim"`
+	// TenantClaim, if set, holds the tenant the token is for, and tokens
+	// without it are rejected. Without it, the issuer's tokens are for
+	// tenant.Default.
+	TenantClaim string `json:"tenantClaim"`
+}
+
+// LoadIssuers reads a JSON array of issuers, as in the file named by
+// OIDC_ISSUERS.
+func LoadIssuers(r io.Reader) ([]IssuerConfig, error) {
+	var issuers []IssuerConfig
+	if err := json.NewDecoder(r).Decode(&issuers); err != nil {
+		return nil, err
+	}
+	return issuers, nil
+}
+
+type issuer struct {
+	IssuerConfig
+	keys keySource
+}
+
+// JWTAuthenticator verifies bearer JWTs from a set of issuers.
+type JWTAuthenticator struct {
+	issuers map[string]*issuer
+	now     func() time.Time
+}
+
+// NewJWTAuthenticator checks the issuers and loads the key sets of those
+// configured with a file. Key sets at a URL are fetched on first use.
+func NewJWTAuthenticator(configs []IssuerConfig) (*JWTAuthenticator, error) {
+	a := &JWTAuthenticator{issuers: map[string]*issuer{}, now: time.Now}
+	for _, cfg := range configs {
+		if cfg.Issuer == "" {
+			return nil, errors.New("issuer without an issuer URL")
+		}
+		if _, dup := a.issuers[cfg.Issuer]; dup {
+			return nil, fmt.Errorf("issuer %s listed twice", cfg.Issuer)
+		}
+		for value, role := range cfg.RoleMap {
+			if _, ok := rolePermissions[role]; !ok {
+				return nil, fmt.Errorf("issuer %s: %q maps to unknown role %q", cfg.Issuer, value, role)
+			}
+		}
+		if cfg.RolesClaim == "" {
+			cfg.RolesClaim = "roles"
+		}
+		if cfg.SubjectClaim == "" {
+			cfg.SubjectClaim = "sub"
+		}
+
+		iss := &issuer{IssuerConfig: cfg}
+		switch {
+		case cfg.JWKSURL != "" && cfg.JWKSFile != "":
+			return nil, fmt.Errorf("issuer %s: set jwksUrl or jwksFile, not both", cfg.Issuer)
+		case cfg.JWKSURL != "":
+			iss.keys = newRemoteKeySet(cfg.JWKSURL)
+		case cfg.JWKSFile != "":
+			set, err := LoadJWKSFile(cfg.JWKSFile)
+			if err != nil {
+				return nil, fmt.Errorf("issuer %s: %w", cfg.Issuer, err)
+			}
+			iss.keys = set
+		default:
+			return nil, fmt.Errorf("issuer %s: jwksUrl or jwksFile is required", cfg.Issuer)
+		}
+		a.issuers[cfg.Issuer] = iss
+	}
+	return a, nil
+}
+
+// Verify checks the signature, issuer, audience and lifetime of token and
+// returns the principal it names. Failures wrap ErrInvalidToken.
+func (a *JWTAuthenticator) Verify(ctx context.Context, token string) (Principal, error) {
+	header, claims, signed, sig, err := splitJWT(token)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	iss, _ := claims["iss"].(string)
+	issuer, ok := a.issuers[iss]
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: unknown issuer %q", ErrInvalidToken, iss)
+	}
+	keys, err := issuer.keys.publicKeys(ctx, header.Kid)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: loading keys of %s: %v", ErrInvalidToken, iss, err)
+	}
+	if !verifySignature(header.Alg, keys, signed, sig) {
+		return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	now := a.now()
+	exp, ok := numericClaim(claims, "exp")
+	if !ok {
+		return Principal{}, fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	}
+	if now.After(exp.Add(clockSkew)) {
+		return Principal{}, fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if nbf, ok := numericClaim(claims, "nbf"); ok && now.Add(clockSkew).Before(nbf) {
+		return Principal{}, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if issuer.Audience != "" && !slices.Contains(stringsClaim(claims["aud"]), issuer.Audience) {
+		return Principal{}, fmt.Errorf("%w: not for audience %s", ErrInvalidToken, issuer.Audience)
+	}
+
+	p := Principal{Issuer: iss}
+	p.Subject, _ = claimPath(claims, issuer.SubjectClaim).(string)
+	if p.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: %s is required", ErrInvalidToken, issuer.SubjectClaim)
+	}
+	if issuer.TenantClaim != "" {
+		p.Tenant, _ = claimPath(claims, issuer.TenantClaim).(string)
+		if p.Tenant == "" {
+			return Principal{}, fmt.Errorf("%w: %s is required", ErrInvalidToken, issuer.TenantClaim)
+		}
+	}
+	for _, value := range stringsClaim(claimPath(claims, issuer.RolesClaim)) {
+		role, ok := issuer.RoleMap[value]
+		if !ok {
+			role = Role(value)
+		}
+		if _, known := rolePermissions[role]; known && !slices.Contains(p.Roles, role) {
+			p.Roles = append(p.Roles, role)
+		}
+	}
+	return p, nil
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+func splitJWT(token string) (jwtHeader, map[string]interface{}, []byte, []byte, error) {
+	var header jwtHeader
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return header, nil, nil, nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(rawHeader, &header) != nil {
+		return header, nil, nil, nil, fmt.Errorf("%w: malformed header", ErrInvalidToken)
+	}
+	rawClaims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	var claims map[string]interface{}
+	if err != nil || json.Unmarshal(rawClaims, &claims) != nil {
+		return header, nil, nil, nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return header, nil, nil, nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+	return header, claims, []byte(parts[0] + "." + parts[1]), sig, nil
+}
+
+// verifySignature checks sig with any of keys. Only asymmetric algorithms
+// are accepted, so a public key can never be used as an HMAC secret.
+func verifySignature(alg string, keys []crypto.PublicKey, signed, sig []byte) bool {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "ES512":
+		hash = crypto.SHA512
+	default:
+		return false
+	}
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	for _, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			if alg[0] == 'R' && rsa.VerifyPKCS1v15(key, hash, digest, sig) == nil {
+				return true
+			}
+		case *ecdsa.PublicKey:
+			size := (key.Curve.Params().BitSize + 7) / 8
+			if alg[0] == 'E' && len(sig) == 2*size {
+				r := new(big.Int).SetBytes(sig[:size])
+				s := new(big.Int).SetBytes(sig[size:])
+				if ecdsa.Verify(key, digest, r, s) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func numericClaim(claims map[string]interface{}, name string) (time.Time, bool) {
+	v, ok := claims[name].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(v), 0), true
+}
+
+// claimPath follows a dotted path through nested claim objects.
+func claimPath(claims map[string]interface{}, path string) interface{} {
+	var v interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = obj[name]
+	}
+	return v
+}
+
+// stringsClaim reads a claim that is a string or an array of strings. A
+// string may also list values separated by spaces, as scope does.
+func stringsClaim(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		var out []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// Authenticate verifies bearer JWTs and stores their principal in the
+// request context, and turns on the checks of Require, RequireUser and
+// AllowedFor. Requests without a bearer JWT pass through unauthenticated,
+// so other bearer tokens such as the admin token keep working; requests
+// with one that fails verification are rejected with 401.
+func Authenticate(a *JWTAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := enforce(r.Context())
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if ok && strings.Count(bearer, ".") == 2 {
+				p, err := a.Verify(ctx, bearer)
+				if err != nil {
+					w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+					http.Error(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				ctx = WithPrincipal(ctx, p)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// TenantFromPrincipal reads the tenant from the token of an authenticated
+// request; see IssuerConfig.TenantClaim.
+func TenantFromPrincipal(r *http.Request) string {
+	p, _ := PrincipalFrom(r.Context())
+	return p.Tenant
+}
@@ -0,0 +1,288 @@
+package middleware_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/middleware"
+)
+
+const testIssuer = "https://login.example.com/"
+
+// testKeys is a locally generated key set with one RSA and one EC key.
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate EC key: %v", err)
+	}
+	return testKeys{rsa: rsaKey, ec: ecKey}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (k testKeys) jwks() []byte {
+	ecPub, _ := k.ec.PublicKey.Bytes()
+	data, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecPub[1:33]), "y": b64(ecPub[33:])},
+	}})
+	return data
+}
+
+// sign returns a token signed with the RSA key (RS256) or, for kid "ec-1",
+// the EC key (ES256).
+func (k testKeys) sign(t *testing.T, kid string, claims map[string]any) string {
+	t.Helper()
+	alg := "RS256"
+	if kid == "ec-1" {
+		alg = "ES256"
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := b64(header) + "." + b64(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var sig []byte
+	var err error
+	if alg == "ES256" {
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		if err == nil {
+			sig = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+		}
+	} else {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	}
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed + "." + b64(sig)
+}
+
+func claims(sub string, roles ...string) map[string]any {
+	return map[string]any{
+		"iss":          testIssuer,
+		"aud":          []string{"cyber-go", "other"},
+		"sub":          sub,
+		"exp":          time.Now().Add(time.Hour).Unix(),
+		"realm_access": map[string]any{"roles": roles},
+		"tenant":       "acme",
+	}
+}
+
+func fileAuthenticator(t *testing.T, keys testKeys) *middleware.JWTAuthenticator {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, keys.jwks(), 0o600); err != nil {
+		t.Fatalf("failed to write key set: %v", err)
+	}
+	auth, err := middleware.NewJWTAuthenticator([]middleware.IssuerConfig{{
+		Issuer:      testIssuer,
+		Audience:    "cyber-go",
+		JWKSFile:    path,
+		RolesClaim:  "realm_access.roles",
+		RoleMap:     map[string]middleware.Role{"cyber-uw": middleware.RoleUnderwriter},
+		TenantClaim: "tenant",
+	}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	return auth
+}
+
+func TestJWTAuthenticatorVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	auth := fileAuthenticator(t, keys)
+	ctx := context.Background()
+
+	p, err := auth.Verify(ctx, keys.sign(t, "rsa-1", claims("12", "cyber-uw", "applicant", "unrelated")))
+	if err != nil {
+		t.Fatalf("expected a valid RS256 token, got %v", err)
+	}
+	if p.Subject != "12" || p.Tenant != "acme" || len(p.Roles) != 2 || p.Roles[0] != middleware.RoleUnderwriter {
+		t.Errorf("unexpected principal: %+v", p)
+	}
+	if p, err := auth.Verify(ctx, keys.sign(t, "ec-1", claims("13", "admin"))); err != nil || !p.Can(middleware.PermAdmin) {
+		t.Errorf("expected a valid ES256 admin token, got %+v, %v", p, err)
+	}
+
+	expired := claims("12", "applicant")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	otherIssuer := claims("12", "applicant")
+	otherIssuer["iss"] = "https://evil.example.com/"
+	otherAudience := claims("12", "applicant")
+	otherAudience["aud"] = "someone-else"
+	noExpiry := claims("12", "applicant")
+	delete(noExpiry, "exp")
+
+	valid := keys.sign(t, "rsa-1", claims("12", "applicant"))
+	parts := strings.Split(valid, ".")
+	tampered, _ := json.Marshal(claims("99", "admin"))
+	unsigned := b64([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+
+	for name, token := range map[string]string{
+		"expired":        keys.sign(t, "rsa-1", expired),
+		"other issuer":   keys.sign(t, "rsa-1", otherIssuer),
+		"other audience": keys.sign(t, "rsa-1", otherAudience),
+		"no expiry":      keys.sign(t, "rsa-1", noExpiry),
+		"unknown kid":    keys.sign(t, "rsa-2", claims("12", "applicant")),
+		"tampered":       parts[0] + "." + b64(tampered) + "." + parts[2],
+		"alg none":       unsigned,
+		"garbage":        "not.a.jwt",
+	} {
+		if _, err := auth.Verify(ctx, token); !errors.Is(err, middleware.ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
+
+func TestJWTAuthenticatorFetchesKeySet(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(keys.jwks())
+	}))
+	defer srv.Close()
+
+	auth, err := middleware.NewJWTAuthenticator([]middleware.IssuerConfig{{Issuer: testIssuer, JWKSURL: srv.URL}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := auth.Verify(context.Background(), keys.sign(t, "ec-1", claims("12", "applicant"))); err != nil {
+			t.Fatalf("expected a valid token, got %v", err)
+		}
+	}
+	// Unknown key ids refetch at most once a minute.
+	auth.Verify(context.Background(), keys.sign(t, "rotated", claims("12", "applicant")))
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected the key set to be fetched once, got %d", n)
+	}
+}
+
+func TestJWTAuthenticatorSharesKeySetFetches(t *testing.T) {
+	keys := newTestKeys(t)
+	var fetches atomic.Int32
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+		w.Write(keys.jwks())
+	}))
+	defer srv.Close()
+
+	auth, err := middleware.NewJWTAuthenticator([]middleware.IssuerConfig{{Issuer: testIssuer, JWKSURL: srv.URL}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	token := keys.sign(t, "ec-1", claims("12", "applicant"))
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := auth.Verify(context.Background(), token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("expected concurrent requests to share the fetched key set, got %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("expected one fetch for concurrent requests, got %d", n)
+	}
+
+	// While the issuer is down, failed fetches are not retried either.
+	down.Store(true)
+	auth, _ = middleware.NewJWTAuthenticator([]middleware.IssuerConfig{{Issuer: testIssuer, JWKSURL: srv.URL}})
+	for i := 0; i < 3; i++ {
+		if _, err := auth.Verify(context.Background(), token); !errors.Is(err, middleware.ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken while the issuer is down, got %v", err)
+		}
+	}
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("expected one failed fetch, got %d", n-1)
+	}
+}
+
+func TestAuthenticateEnforcesPermissions(t *testing.T) {
+	keys := newTestKeys(t)
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	r := mux.NewRouter()
+	r.Use(middleware.AdminToken("s3cret"))
+	r.Use(middleware.Authenticate(fileAuthenticator(t, keys)))
+	r.Handle("/questions", middleware.Require(middleware.PermReadQuestions)(ok))
+	r.Handle("/result/{userID}", middleware.RequireUser("userID")(ok))
+	r.Handle("/admin/rescore", middleware.RequireAdmin(ok))
+
+	applicant := "Bearer " + keys.sign(t, "rsa-1", claims("12", "applicant"))
+	underwriter := "Bearer " + keys.sign(t, "rsa-1", claims("uw-1", "cyber-uw"))
+	for _, tc := range []struct {
+		path, auth string
+		want       int
+	}{
+		{"/questions", "", http.StatusUnauthorized},
+		{"/questions", applicant, http.StatusOK},
+		{"/questions", "Bearer " + keys.sign(t, "rsa-1", claims("12")), http.StatusForbidden},
+		{"/questions", "Bearer forged.jwt.token", http.StatusUnauthorized},
+		{"/result/12", applicant, http.StatusOK},
+		{"/result/13", applicant, http.StatusForbidden},
+		{"/result/13", underwriter, http.StatusOK},
+		{"/result/13", "Bearer s3cret", http.StatusOK},
+		{"/admin/rescore", underwriter, http.StatusUnauthorized},
+		{"/admin/rescore", "Bearer " + keys.sign(t, "ec-1", claims("ops", "admin")), http.StatusOK},
+	} {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.auth != "" {
+			req.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s with %.20q: expected %d, got %d", tc.path, tc.auth, tc.want, w.Code)
+		}
+	}
+}
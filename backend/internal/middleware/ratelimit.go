@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"cyber-go/internal/observability"
+	"cyber-go/internal/util"
+)
+
+// DefaultRoute names the limit of routes without one of their own.
+const DefaultRoute = "default"
+
+// Limit is a token bucket: Burst requests at once, refilled at Rate
+// requests per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// window is how long an empty bucket takes to fill up.
+func (l Limit) window() time.Duration {
+	return time.Duration(float64(l.Burst) / l.Rate * float64(time.Second))
+}
+
+var rateUnits = map[string]time.Duration{"s": time.Second, "m": time.Minute, "h": time.Hour}
+
+// ParseLimit parses "N/unit" (unit s, m or h) with an optional ":burst",
+// such as "30/m:5". The burst defaults to N.
+func ParseLimit(s string) (Limit, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+	count, unit, ok := strings.Cut(rate, "/")
+	per, known := rateUnits[unit]
+	n, err := strconv.Atoi(count)
+	if !ok || !known || err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: expected requests per s, m or h, such as 30/m", s)
+	}
+	l := Limit{Rate: float64(n) / per.Seconds(), Burst: n}
+	if hasBurst {
+		if l.Burst, err = strconv.Atoi(burst); err != nil || l.Burst <= 0 {
+			return Limit{}, fmt.Errorf("limit %q: burst must be a positive number", s)
+		}
+	}
+	return l, nil
+}
+
+// ParseRateLimits parses "route=limit" pairs separated by commas, as in the
+// RATE_LIMITS environment variable. Routes are named by their path
+// template, such as /result/{userID}, or DefaultRoute.
+func ParseRateLimits(s string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		route, limit, ok := strings.Cut(pair, "=")
+		if !ok || route == "" {
+			return nil, errors.New("expected route=limit pairs separated by commas")
+		}
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", route, err)
+		}
+		limits[route] = l
+	}
+	return limits, nil
+}
+
+// RateLimitResult is the state of a bucket after a token was asked for.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// RetryAfter is how long until the next token, when none was left.
+	RetryAfter time.Duration
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// RateLimitStore keeps the token buckets of RateLimit. MemoryRateLimitStore
+// keeps them in process; implement it on a shared backend to limit clients
+// across instances.
+type RateLimitStore interface {
+	// Take takes a token from the bucket of key, which holds limit, if
+	// one is left at now.
+	Take(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error)
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// fill returns the tokens in b at now.
+func (b *bucket) fill(now time.Time) float64 {
+	return math.Min(float64(b.limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate)
+}
+
+// MemoryRateLimitStore implements RateLimitStore in process memory. Buckets
+// that filled up again are dropped now and then.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// NewMemoryRateLimitStore returns an empty in-process store.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: map[string]*bucket{}}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit Limit, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.swept) > time.Minute {
+		for k, b := range s.buckets {
+			if b.fill(now) >= float64(b.limit.Burst) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok || b.limit != limit {
+		b = &bucket{tokens: float64(limit.Burst), updated: now, limit: limit}
+		s.buckets[key] = b
+	}
+	b.tokens, b.updated = b.fill(now), now
+
+	res := RateLimitResult{Allowed: b.tokens >= 1}
+	if res.Allowed {
+		b.tokens--
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return res, nil
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// RateLimitClient returns the client a request is limited as, and what kind
+// of key that is: the API key or user the request was authenticated as, or
+// else its remote IP. Behind a proxy, all requests share the proxy's IP.
+func RateLimitClient(r *http.Request) (key, kind string) {
+	if p, ok := PrincipalFrom(r.Context()); ok {
+		if p.APIKey != "" {
+			return "apikey:" + p.APIKey, "apikey"
+		}
+		if p.Subject != "" {
+			return "user:" + p.Issuer + "|" + p.Subject, "user"
+		}
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip, "ip"
+}
+
+// RateLimit limits each client (see RateLimitClient) to the limit of the
+// route it calls, by path template. Routes without a limit of their own
+// share one bucket per client with the DefaultRoute limit, or are not
+// limited if there is none. Requests are answered with RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers; those over the limit
+// with 429 and Retry-After, and are counted in the
+// rate_limit_rejections_total metric. Requests are let through if the store
+// fails, so an outage of a shared store does not take the API down. Install
+// it after the authenticating middleware.
+func RateLimit(store RateLimitStore, limits map[string]Limit) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := DefaultRoute
+			if current := mux.CurrentRoute(r); current != nil {
+				if tpl, err := current.GetPathTemplate(); err == nil {
+					if _, ok := limits[tpl]; ok {
+						route = tpl
+					}
+				}
+			}
+			limit, ok := limits[route]
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			client, kind := RateLimitClient(r)
+			res, err := store.Take(r.Context(), route+" "+client, limit, time.Now())
+			if err != nil {
+				util.Logger.Warn("rate limit store failed, request not limited", zap.String("route", route), zap.Error(err))
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+			h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(limit.window())))
+			if !res.Allowed {
+				observability.CountRateLimitRejection(route, kind)
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
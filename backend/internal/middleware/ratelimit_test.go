@@ -0,0 +1,103 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"cyber-go/internal/middleware"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := middleware.ParseRateLimits("/submit=30/m:5, default=10/s")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if l := limits["/submit"]; l.Rate != 0.5 || l.Burst != 5 {
+		t.Errorf("expected 0.5/s with a burst of 5, got %+v", l)
+	}
+	if l := limits[middleware.DefaultRoute]; l.Rate != 10 || l.Burst != 10 {
+		t.Errorf("expected the burst to default to the count, got %+v", l)
+	}
+	for _, s := range []string{"/submit", "/submit=30", "/submit=30/d", "/submit=0/s", "/submit=5/s:0", "=5/s"} {
+		if _, err := middleware.ParseRateLimits(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestMemoryRateLimitStoreRefills(t *testing.T) {
+	store := middleware.NewMemoryRateLimitStore()
+	ctx := context.Background()
+	limit := middleware.Limit{Rate: 1, Burst: 2}
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if res, _ := store.Take(ctx, "k", limit, now); !res.Allowed || res.Remaining != 1-i {
+			t.Fatalf("request %d: expected to be allowed, got %+v", i, res)
+		}
+	}
+	res, _ := store.Take(ctx, "k", limit, now)
+	if res.Allowed || res.RetryAfter != time.Second || res.Reset != 2*time.Second {
+		t.Errorf("expected an empty bucket refilling in 1s, got %+v", res)
+	}
+	if res, _ := store.Take(ctx, "other", limit, now); !res.Allowed {
+		t.Errorf("expected buckets to be kept per key, got %+v", res)
+	}
+	if res, _ := store.Take(ctx, "k", limit, now.Add(time.Second)); !res.Allowed {
+		t.Errorf("expected a token after a second, got %+v", res)
+	}
+}
+
+func TestRateLimitPerRouteAndClient(t *testing.T) {
+	limits := map[string]middleware.Limit{"/submit": {Rate: 1.0 / 3600, Burst: 1}}
+	r := mux.NewRouter()
+	// Stands in for the authenticating middleware.
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := r.Header.Get("X-Test-Key"); id != "" {
+				r = r.WithContext(middleware.WithPrincipal(r.Context(), middleware.Principal{APIKey: id}))
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	r.Use(middleware.RateLimit(middleware.NewMemoryRateLimitStore(), limits))
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+	r.HandleFunc("/submit", ok)
+	r.HandleFunc("/questions", ok)
+	do := func(path, ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, nil)
+		req.RemoteAddr = ip + ":5000"
+		if key != "" {
+			req.Header.Set("X-Test-Key", key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("/submit", "10.0.0.1", "")
+	if w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "1" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("expected the first request with RateLimit headers, got %d %v", w.Code, w.Header())
+	}
+	w = do("/submit", "10.0.0.1", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "3600" || w.Header().Get("RateLimit-Reset") != "3600" {
+		t.Errorf("expected 429 with Retry-After, got %d %v", w.Code, w.Header())
+	}
+	if w := do("/submit", "10.0.0.2", ""); w.Code != http.StatusOK {
+		t.Errorf("expected another IP to have its own bucket, got %d", w.Code)
+	}
+	if w := do("/submit", "10.0.0.1", "k1"); w.Code != http.StatusOK {
+		t.Errorf("expected an API key to have its own bucket, got %d", w.Code)
+	}
+	if w := do("/submit", "10.0.0.3", "k1"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected an API key to be limited from any IP, got %d", w.Code)
+	}
+	if w := do("/questions", "10.0.0.1", ""); w.Code != http.StatusOK || w.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("expected routes without a limit not to be limited, got %d %v", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
+)
+
+// TenantHeader names the tenant of a request that carries no tenant token.
+const TenantHeader = "X-Tenant-ID"
+
+// TenantResolver returns the tenant a request names, or "" if it names none.
+type TenantResolver func(r *http.Request) string
+
+// TenantFromHeader reads the tenant from the X-Tenant-ID header. The header
+// is not a credential: only trust it behind a gateway that sets it.
+func TenantFromHeader(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(TenantHeader))
+}
+
+// TenantFromTokens maps the bearer token of a request to its tenant.
+// Tokens not in tokens name no tenant, so other bearer tokens such as the
+// admin token pass through.
+func TenantFromTokens(tokens map[string]string) TenantResolver {
+	return func(r *http.Request) string {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return ""
+		}
+		return tokens[bearer]
+	}
+}
+
+// ParseTenantTokens parses "token=tenant" pairs separated by commas, as in
+// the TENANT_TOKENS environment variable.
+func ParseTenantTokens(s string) (map[string]string, error) {
+	tokens := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		token, id, ok := strings.Cut(pair, "=")
+		if !ok || token == "" || id == "" {
+			return nil, errors.New("expected token=tenant pairs separated by commas")
+		}
+		tokens[token] = id
+	}
+	return tokens, nil
+}
+
+// Tenant scopes every request to a tenant, which the repositories read
+// from the context (see tenant.FromContext). The tenant of an
+// authenticated request is that of its principal, tenant.Default if it
+// names none. Otherwise each resolver is asked in turn, and requests naming
+// no tenant are served for tenant.Default. Requests whose resolvers
+// disagree, with each other or with the principal, say a token and a
+// header for another tenant, are rejected with 403, and unknown tenants
+// with 404.
+func Tenant(tenants repositories.TenantRepository, resolvers ...TenantResolver) func(http.Handler) http.Handler {
+	// Tenants are never deleted, so known ones are not looked up again.
+	var known sync.Map
+
+	exists := func(ctx context.Context, id string) (bool, error) {
+		if _, ok := known.Load(id); ok {
+			return true, nil
+		}
+		_, err := tenants.GetTenant(ctx, id)
+		if errors.Is(err, repositories.ErrNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		known.Store(id, true)
+		return true, nil
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := ""
+			if p, ok := PrincipalFrom(r.Context()); ok {
+				id = p.Tenant
+				if id == "" {
+					id = tenant.Default
+				}
+			}
+			for _, resolve := range resolvers {
+				named := resolve(r)
+				if named == "" {
+					continue
+				}
+				if id != "" && named != id {
+					http.Error(w, "Conflicting tenants", http.StatusForbidden)
+					return
+				}
+				id = named
+			}
+			if id == "" {
+				id = tenant.Default
+			}
+
+			ok, err := exists(r.Context(), id)
+			if err != nil {
+				http.Error(w, "Database query error", http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				http.Error(w, "Unknown tenant", http.StatusNotFound)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.WithID(r.Context(), id)))
+		})
+	}
+}
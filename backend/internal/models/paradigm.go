@@ -1,16 +1,305 @@
 package models
 
-import "github.com/graphql-go/graphql"
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+var ConditionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Condition",
+	Fields: graphql.Fields{
+		"questionId": &graphql.Field{Type: graphql.Int},
+		"anyOf":      &graphql.Field{Type: graphql.NewList(graphql.String)},
+	},
+})
+
+var CurveStepType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "CurveStep",
+	Fields: graphql.Fields{
+		"from":     &graphql.Field{Type: graphql.Float},
+		"fraction": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var CurveType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Curve",
+	Fields: graphql.Fields{
+		"kind":         &graphql.Field{Type: graphql.String},
+		"steps":        &graphql.Field{Type: graphql.NewList(CurveStepType)},
+		"from":         &graphql.Field{Type: graphql.Float},
+		"to":           &graphql.Field{Type: graphql.Float},
+		"halfLifeDays": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var OptionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Option",
+	Fields: graphql.Fields{
+		"label":     &graphql.Field{Type: graphql.String},
+		"score":     &graphql.Field{Type: graphql.Int},
+		"exclusive": &graphql.Field{Type: graphql.Boolean},
+		"negative":  &graphql.Field{Type: graphql.Boolean},
+		"dontKnow":  &graphql.Field{Type: graphql.Boolean},
+	},
+})
 
 var QuestionType = graphql.NewObject(graphql.ObjectConfig{
 	Name: "Question",
 	Fields: graphql.Fields{
-		"id":         &graphql.Field{Type: graphql.Int},
-		"paradigmId": &graphql.Field{Type: graphql.Int},
-		"text":       &graphql.Field{Type: graphql.String},
-		"selector":   &graphql.Field{Type: graphql.String},
-		"options":    &graphql.Field{Type: graphql.NewList(graphql.String)},
-		"weight":     &graphql.Field{Type: graphql.Int},
+		"id": &graphql.Field{Type: graphql.Int},
+		"paradigmId": &graphql.Field{
+			Type: graphql.Int,
+			// Question.Paradigm holds the paradigm id as a string.
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				q, _ := p.Source.(Question)
+				id, err := strconv.Atoi(q.Paradigm)
+				if err != nil {
+					return nil, nil
+				}
+				return id, nil
+			},
+		},
+		"text":          &graphql.Field{Type: graphql.String},
+		"selector":      &graphql.Field{Type: graphql.String},
+		"options":       &graphql.Field{Type: graphql.NewList(graphql.String)},
+		"choices":       &graphql.Field{Type: graphql.NewList(OptionType)},
+		"minSelections": &graphql.Field{Type: graphql.Int},
+		"maxSelections": &graphql.Field{Type: graphql.Int},
+		"weight":        &graphql.Field{Type: graphql.Int},
+		"required":      &graphql.Field{Type: graphql.Boolean},
+		"conditions":    &graphql.Field{Type: graphql.NewList(ConditionType)},
+		"curve":         &graphql.Field{Type: CurveType},
+		"position":      &graphql.Field{Type: graphql.Int},
+		"retired":       &graphql.Field{Type: graphql.Boolean},
+		"revision":      &graphql.Field{Type: graphql.Int},
+	},
+})
+
+var ParadigmType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Paradigm",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.Int},
+		"name":        &graphql.Field{Type: graphql.String},
+		"description": &graphql.Field{Type: graphql.String},
+		"position":    &graphql.Field{Type: graphql.Int},
+		"retired":     &graphql.Field{Type: graphql.Boolean},
+		"revision":    &graphql.Field{Type: graphql.Int},
+	},
+})
+
+// Input types mirror the JSON fields of Paradigm and Question so mutation
+// arguments decode into the same structs as the REST payloads.
+
+var ConditionInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ConditionInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"questionId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"anyOf":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.String)},
+	},
+})
+
+var CurveStepInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "CurveStepInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"from":     &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"fraction": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+	},
+})
+
+var CurveInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "CurveInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"kind":         &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"steps":        &graphql.InputObjectFieldConfig{Type: graphql.NewList(CurveStepInputType)},
+		"from":         &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"to":           &graphql.InputObjectFieldConfig{Type: graphql.Float},
+		"halfLifeDays": &graphql.InputObjectFieldConfig{Type: graphql.Float},
+	},
+})
+
+var OptionInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "OptionInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"label":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"score":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"exclusive": &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"negative":  &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"dontKnow":  &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+	},
+})
+
+var QuestionInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "QuestionInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"paradigm":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"text":          &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"selector":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"options":       &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.String)},
+		"choices":       &graphql.InputObjectFieldConfig{Type: graphql.NewList(OptionInputType)},
+		"minSelections": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"maxSelections": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"weight":        &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"required":      &graphql.InputObjectFieldConfig{Type: graphql.Boolean},
+		"conditions":    &graphql.InputObjectFieldConfig{Type: graphql.NewList(ConditionInputType)},
+		"curve":         &graphql.InputObjectFieldConfig{Type: CurveInputType},
+		"position":      &graphql.InputObjectFieldConfig{Type: graphql.Int},
+	},
+})
+
+var ParadigmInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "ParadigmInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"position":    &graphql.InputObjectFieldConfig{Type: graphql.Int},
+	},
+})
+
+var OrderInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "OrderInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"id":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"revision": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+	},
+})
+
+// AnswerInputType carries one answer: value for radio, dropdown and date
+// questions, values for checkboxes and number for number questions.
+var AnswerInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "AnswerInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"questionId": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Int)},
+		"value":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"values":     &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+		"number":     &graphql.InputObjectFieldConfig{Type: graphql.Float},
+	},
+})
+
+var AnswerErrorType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "AnswerError",
+	Fields: graphql.Fields{
+		"questionId": &graphql.Field{Type: graphql.Int},
+		"code":       &graphql.Field{Type: graphql.String},
+		"message":    &graphql.Field{Type: graphql.String},
+	},
+})
+
+var ParadigmScoreType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ParadigmScore",
+	Fields: graphql.Fields{
+		"paradigm":   &graphql.Field{Type: graphql.String},
+		"points":     &graphql.Field{Type: graphql.Int},
+		"maxPoints":  &graphql.Field{Type: graphql.Int},
+		"percentage": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var QuestionTraceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "QuestionTrace",
+	Fields: graphql.Fields{
+		"questionId": &graphql.Field{Type: graphql.Int},
+		"paradigm":   &graphql.Field{Type: graphql.String},
+		"answer": &graphql.Field{
+			Type:        graphql.String,
+			Description: "The answer as JSON",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				t, _ := p.Source.(QuestionTrace)
+				if t.Answer == nil {
+					return nil, nil
+				}
+				data, err := json.Marshal(t.Answer)
+				return string(data), err
+			},
+		},
+		"rule":      &graphql.Field{Type: graphql.String},
+		"weight":    &graphql.Field{Type: graphql.Int},
+		"points":    &graphql.Field{Type: graphql.Int},
+		"maxPoints": &graphql.Field{Type: graphql.Int},
+		"note":      &graphql.Field{Type: graphql.String},
+	},
+})
+
+var DecisionReasonType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "DecisionReason",
+	Fields: graphql.Fields{
+		"ruleId": &graphql.Field{Type: graphql.String},
+		"action": &graphql.Field{Type: graphql.String},
+		"reason": &graphql.Field{Type: graphql.String},
+	},
+})
+
+var DecisionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Decision",
+	Fields: graphql.Fields{
+		"outcome": &graphql.Field{Type: graphql.String},
+		"reasons": &graphql.Field{Type: graphql.NewList(DecisionReasonType)},
+	},
+})
+
+var ResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Result",
+	Fields: graphql.Fields{
+		"submissionId":         &graphql.Field{Type: graphql.String},
+		"questionnaireVersion": &graphql.Field{Type: graphql.Int},
+		"totalScore":           &graphql.Field{Type: graphql.Int},
+		"policy":               &graphql.Field{Type: graphql.String},
+		"decision":             &graphql.Field{Type: DecisionType},
+		"paradigms":            &graphql.Field{Type: graphql.NewList(ParadigmScoreType)},
+		"trace":                &graphql.Field{Type: graphql.NewList(QuestionTraceType)},
+		"submittedAt":          &graphql.Field{Type: graphql.DateTime},
+	},
+})
+
+var ParadigmDeltaType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ParadigmDelta",
+	Fields: graphql.Fields{
+		"paradigm":   &graphql.Field{Type: graphql.String},
+		"points":     &graphql.Field{Type: graphql.Int},
+		"percentage": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+var AssessmentDeltaType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "AssessmentDelta",
+	Fields: graphql.Fields{
+		"score":          &graphql.Field{Type: graphql.Int},
+		"previousPolicy": &graphql.Field{Type: graphql.String},
+		"policyChanged":  &graphql.Field{Type: graphql.Boolean},
+		"paradigms":      &graphql.Field{Type: graphql.NewList(ParadigmDeltaType)},
+	},
+})
+
+var AssessmentType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Assessment",
+	Fields: graphql.Fields{
+		"submissionId":         &graphql.Field{Type: graphql.String},
+		"questionnaireVersion": &graphql.Field{Type: graphql.Int},
+		"totalScore":           &graphql.Field{Type: graphql.Int},
+		"policy":               &graphql.Field{Type: graphql.String},
+		"decision":             &graphql.Field{Type: graphql.String},
+		"submittedAt":          &graphql.Field{Type: graphql.DateTime},
+		"delta":                &graphql.Field{Type: AssessmentDeltaType},
+	},
+})
+
+var PolicyTierType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PolicyTier",
+	Fields: graphql.Fields{
+		"name":          &graphql.Field{Type: graphql.String},
+		"minScore":      &graphql.Field{Type: graphql.Int},
+		"coverageLimit": &graphql.Field{Type: graphql.Float},
+		"description":   &graphql.Field{Type: graphql.String},
+	},
+})
+
+var TierTableType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "TierTable",
+	Fields: graphql.Fields{
+		"version": &graphql.Field{Type: graphql.Int},
+		"tiers":   &graphql.Field{Type: graphql.NewList(PolicyTierType)},
 	},
 })
 
@@ -18,6 +307,47 @@ type Paradigm struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
+	// Position orders paradigms; retired paradigms are kept for history
+	// but no longer served.
+	Position int  `json:"position,omitempty"`
+	Retired  bool `json:"retired,omitempty"`
+	// Revision is bumped on every edit and guards against lost updates.
+	Revision int `json:"revision,omitempty"`
+}
+
+// Condition makes a question visible only when another question was
+// answered with one of the listed values.
+type Condition struct {
+	QuestionID int      `json:"questionId"`
+	AnyOf      []string `json:"anyOf"`
+}
+
+// CurveStep awards Fraction of the weight to values of at least From.
+type CurveStep struct {
+	From     float64 `json:"from"`
+	Fraction float64 `json:"fraction"`
+}
+
+// Curve maps a number, or a date's age in days, to a fraction of the weight.
+// Kind is "steps" (bands), "linear" (From scores 0, To scores the full
+// weight) or "decay" (halves every HalfLifeDays).
+type Curve struct {
+	Kind         string      `json:"kind"`
+	Steps        []CurveStep `json:"steps,omitempty"`
+	From         float64     `json:"from,omitempty"`
+	To           float64     `json:"to,omitempty"`
+	HalfLifeDays float64     `json:"halfLifeDays,omitempty"`
+}
+
+// Option is an answer choice with its own score. Negative options deduct
+// their score, DontKnow options always score zero and an Exclusive option
+// cannot be combined with other checkbox selections.
+type Option struct {
+	Label     string `json:"label"`
+	Score     int    `json:"score"`
+	Exclusive bool   `json:"exclusive,omitempty"`
+	Negative  bool   `json:"negative,omitempty"`
+	DontKnow  bool   `json:"dontKnow,omitempty"`
 }
 
 type Question struct {
@@ -27,6 +357,22 @@ type Question struct {
 	Weight   int      `json:"weight"`
 	Selector string   `json:"selector"`
 	Options  []string `json:"options"`
+	// Choices carry explicit per-option scores. When set, Options holds
+	// their labels and scoring ignores option order.
+	Choices []Option `json:"choices,omitempty"`
+	// MinSelections and MaxSelections bound checkbox answers; 0 means no limit.
+	MinSelections int  `json:"minSelections,omitempty"`
+	MaxSelections int  `json:"maxSelections,omitempty"`
+	Required      bool `json:"required"`
+	// Conditions must all hold for the question to be asked and scored.
+	Conditions []Condition `json:"conditions,omitempty"`
+	// Curve scores "number" and "date" questions.
+	Curve *Curve `json:"curve,omitempty"`
+	// Position orders the catalog. Retired questions are no longer asked.
+	Position int  `json:"position,omitempty"`
+	Retired  bool `json:"retired,omitempty"`
+	// Revision is bumped on every edit and guards against lost updates.
+	Revision int `json:"revision,omitempty"`
 }
 
 type Answer struct {
@@ -34,7 +380,323 @@ type Answer struct {
 	Response   interface{} `json:"response"`
 }
 
+// Questionnaire is an immutable, published snapshot of the question catalog.
+// Version 0 stands for the live questions table when nothing is published.
+type Questionnaire struct {
+	Version     int        `json:"version"`
+	Label       string     `json:"label"`
+	Current     bool       `json:"current"`
+	PublishedAt time.Time  `json:"publishedAt"`
+	Questions   []Question `json:"questions"`
+}
+
+// AnswerError describes why the answer to one question was rejected.
+type AnswerError struct {
+	QuestionID int    `json:"questionId"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+// PolicyTier is one row of the policy_tiers table. A tier applies to every
+// score from MinScore up to the MinScore of the next tier.
+type PolicyTier struct {
+	Name          string `json:"name"`
+	MinScore      int    `json:"minScore"`
+	CoverageLimit int64  `json:"coverageLimit"`
+	Description   string `json:"description"`
+}
+
+// TierTable is one published version of the policy tiers, ordered by MinScore.
+type TierTable struct {
+	Version int          `json:"version"`
+	Tiers   []PolicyTier `json:"tiers"`
+}
+
+// UnderwritingRule refers or declines an application when all of its
+// conditions hold, whatever the score.
+type UnderwritingRule struct {
+	ID     string      `json:"id"`
+	Action string      `json:"action"`
+	Reason string      `json:"reason"`
+	When   []Condition `json:"when"`
+}
+
+// DecisionReason records one underwriting rule that fired.
+type DecisionReason struct {
+	RuleID string `json:"ruleId"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// Decision is the underwriting outcome: accept, refer or decline.
+type Decision struct {
+	Outcome string           `json:"outcome"`
+	Reasons []DecisionReason `json:"reasons"`
+}
+
+// ParadigmScore is the share of a result earned within one paradigm.
+type ParadigmScore struct {
+	Paradigm   string  `json:"paradigm"`
+	Points     int     `json:"points"`
+	MaxPoints  int     `json:"maxPoints"`
+	Percentage float64 `json:"percentage"`
+}
+
+// QuestionTrace explains how one question contributed to a result.
+type QuestionTrace struct {
+	QuestionID int         `json:"questionId"`
+	Paradigm   string      `json:"paradigm"`
+	Answer     interface{} `json:"answer"`
+	Rule       string      `json:"rule"`
+	Weight     int         `json:"weight"`
+	Points     int         `json:"points"`
+	MaxPoints  int         `json:"maxPoints"`
+	Note       string      `json:"note,omitempty"`
+}
+
 type Result struct {
-	TotalScore int    `json:"totalScore"`
-	Policy     string `json:"policy"`
+	SubmissionID string `json:"submissionId,omitempty"`
+	// QuestionnaireVersion is the questionnaire the answers were scored against.
+	QuestionnaireVersion int    `json:"questionnaireVersion"`
+	TotalScore           int    `json:"totalScore"`
+	Policy               string `json:"policy"`
+	// CoverageLimit is the coverage limit of the Policy tier when the
+	// result was scored, 0 for none. It is nil for results scored before
+	// limits were recorded.
+	CoverageLimit *int64          `json:"coverageLimit,omitempty"`
+	Decision      Decision        `json:"decision"`
+	Paradigms     []ParadigmScore `json:"paradigms"`
+	Trace         []QuestionTrace `json:"trace,omitempty"`
+	SubmittedAt   time.Time       `json:"submittedAt"`
+}
+
+// Submission is a stored assessment: the raw answers and what they scored.
+type Submission struct {
+	ID                   string              `json:"id"`
+	UserID               string              `json:"userId"`
+	QuestionnaireVersion int                 `json:"questionnaireVersion"`
+	Answers              map[int]interface{} `json:"answers"`
+	Result               Result              `json:"result"`
+	CreatedAt            time.Time           `json:"createdAt"`
+}
+
+// ParadigmDelta is the change of one paradigm between two assessments.
+type ParadigmDelta struct {
+	Paradigm   string  `json:"paradigm"`
+	Points     int     `json:"points"`
+	Percentage float64 `json:"percentage"`
+}
+
+// AssessmentDelta compares an assessment with the one before it.
+type AssessmentDelta struct {
+	Score          int             `json:"score"`
+	PreviousPolicy string          `json:"previousPolicy"`
+	PolicyChanged  bool            `json:"policyChanged"`
+	Paradigms      []ParadigmDelta `json:"paradigms"`
+}
+
+// Assessment is one entry of a user's assessment history.
+type Assessment struct {
+	SubmissionID         string           `json:"submissionId"`
+	QuestionnaireVersion int              `json:"questionnaireVersion"`
+	TotalScore           int              `json:"totalScore"`
+	Policy               string           `json:"policy"`
+	Decision             string           `json:"decision"`
+	SubmittedAt          time.Time        `json:"submittedAt"`
+	Delta                *AssessmentDelta `json:"delta,omitempty"`
+}
+
+// Assessment session statuses. A draft past its expiry reports
+// SessionExpired and can no longer be changed.
+const (
+	SessionDraft     = "draft"
+	SessionFinalized = "finalized"
+	SessionExpired   = "expired"
+)
+
+// AssessmentSession is an assessment answered over several sittings. It is
+// pinned to the questionnaire version current when it was started.
+type AssessmentSession struct {
+	ID                   string              `json:"id"`
+	UserID               string              `json:"userId"`
+	QuestionnaireVersion int                 `json:"questionnaireVersion"`
+	Status               string              `json:"status"`
+	Answers              map[int]interface{} `json:"answers"`
+	// Revision is bumped on every save and guards against lost updates.
+	Revision     int       `json:"revision"`
+	SubmissionID string    `json:"submissionId,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+	// Progress and Complete are computed when the session is served.
+	Progress []ParadigmProgress `json:"progress,omitempty"`
+	Complete bool               `json:"complete"`
+}
+
+// ParadigmProgress counts the answered questions of one paradigm among
+// those currently visible.
+type ParadigmProgress struct {
+	Paradigm        string `json:"paradigm"`
+	Answered        int    `json:"answered"`
+	Total           int    `json:"total"`
+	RequiredMissing int    `json:"requiredMissing"`
+}
+
+// IdempotencyRecord is the stored response of a request sent with an
+// Idempotency-Key. RequestHash identifies the request it answered.
+type IdempotencyRecord struct {
+	Key          string          `json:"key"`
+	RequestHash  string          `json:"requestHash"`
+	Status       int             `json:"status"`
+	Response     json.RawMessage `json:"response"`
+	SubmissionID string          `json:"submissionId"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// Tenant is a broker or carrier the service is white-labelled for. Each
+// tenant has its own questionnaire versions, tier tables and submissions.
+type Tenant struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// APIKey lets a broker system call the API server to server for a tenant.
+// Only the hash of its secret is stored; the key itself is shown once, when
+// it is created. A DailyQuota of 0 leaves the key unlimited.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Tenant     string     `json:"tenant"`
+	Name       string     `json:"name"`
+	SecretHash string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	DailyQuota int        `json:"dailyQuota,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Active reports whether the key may still be used at now.
+func (k APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// RevenueBandRate prices one revenue band.
+type RevenueBandRate struct {
+	// RatePerMillion is the base premium per 1,000,000 of limit.
+	RatePerMillion float64 `json:"ratePerMillion"`
+	Deductible     int64   `json:"deductible"`
+}
+
+// RatingTable is one published version of the pricing inputs for quotes.
+type RatingTable struct {
+	Version      int                        `json:"version"`
+	Currency     string                     `json:"currency"`
+	RevenueBands map[string]RevenueBandRate `json:"revenueBands"`
+	// Industries not listed use the "other" multiplier.
+	IndustryMultipliers map[string]float64 `json:"industryMultipliers"`
+	// The security multiplier moves linearly from WorstScoreMultiplier at 0%
+	// of the maximum score to BestScoreMultiplier at 100%.
+	WorstScoreMultiplier float64       `json:"worstScoreMultiplier"`
+	BestScoreMultiplier  float64       `json:"bestScoreMultiplier"`
+	MinimumPremium       float64       `json:"minimumPremium"`
+	ValidFor             time.Duration `json:"validFor"`
+}
+
+// OrgProfile describes the applicant organization for pricing.
+type OrgProfile struct {
+	Industry       string `json:"industry"`
+	RevenueBand    string `json:"revenueBand"`
+	RequestedLimit int64  `json:"requestedLimit"`
+}
+
+// PremiumLine is one step of the premium calculation.
+type PremiumLine struct {
+	Name   string  `json:"name"`
+	Factor float64 `json:"factor,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// Quote is a priced offer for one scored submission.
+type Quote struct {
+	ID           string        `json:"id"`
+	SubmissionID string        `json:"submissionId"`
+	UserID       string        `json:"userId"`
+	Policy       string        `json:"policy"`
+	Status       string        `json:"status"`
+	Profile      OrgProfile    `json:"profile"`
+	Limit        int64         `json:"limit"`
+	Deductible   int64         `json:"deductible"`
+	Premium      float64       `json:"premium"`
+	Currency     string        `json:"currency"`
+	Breakdown    []PremiumLine `json:"breakdown"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	ExpiresAt    time.Time     `json:"expiresAt"`
+}
+
+// ScoreDistribution summarizes a set of scores.
+type ScoreDistribution struct {
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// RescoreChange is one applicant whose result differs after a replay.
+type RescoreChange struct {
+	UserID       string `json:"userId"`
+	SubmissionID string `json:"submissionId"`
+	OldScore     int    `json:"oldScore"`
+	NewScore     int    `json:"newScore"`
+	OldPolicy    string `json:"oldPolicy"`
+	NewPolicy    string `json:"newPolicy"`
+	OldDecision  string `json:"oldDecision"`
+	NewDecision  string `json:"newDecision"`
+}
+
+// RescoreReport compares stored results with a replay under a candidate
+// questionnaire and tier table.
+type RescoreReport struct {
+	QuestionnaireVersion int               `json:"questionnaireVersion"`
+	TierVersion          int               `json:"tierVersion"`
+	Applicants           int               `json:"applicants"`
+	TierChanges          int               `json:"tierChanges"`
+	Before               ScoreDistribution `json:"before"`
+	After                ScoreDistribution `json:"after"`
+	MeanShift            float64           `json:"meanShift"`
+	Affected             []RescoreChange   `json:"affected"`
+}
+
+// Bundle is the catalog as authored in a YAML or JSON file: the active
+// paradigms and questions, in order, and optionally the tier table.
+type Bundle struct {
+	Paradigms []Paradigm `json:"paradigms"`
+	Questions []Question `json:"questions"`
+	Tiers     *TierTable `json:"tiers,omitempty"`
+}
+
+// FieldChange is one field that an import changes.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CatalogChange describes one paradigm, question or tier table an import
+// creates, updates or retires.
+type CatalogChange struct {
+	Kind   string        `json:"kind"`
+	ID     int           `json:"id"`
+	Action string        `json:"action"`
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// CatalogPlan is what an import writes: the rows to store, where a zero
+// Revision means a new row, the tier table to add if it changed, and the
+// resulting changes.
+type CatalogPlan struct {
+	Paradigms []Paradigm      `json:"-"`
+	Questions []Question      `json:"-"`
+	Tiers     *TierTable      `json:"-"`
+	Changes   []CatalogChange `json:"changes"`
 }
@@ -24,13 +24,31 @@ var (
 		Help: "Duration of DB queries",
 	})
 
+	// Requests made with each API key, by response status
+	apiKeyRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "api_key_requests_total",
+			Help: "Requests authenticated with an API key",
+		},
+		[]string{"key_id", "status"},
+	)
+
+	// Requests rejected by rate limits, by route and kind of client
+	rateLimitRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rate_limit_rejections_total",
+			Help: "Requests rejected for exceeding a rate limit",
+		},
+		[]string{"route", "client"},
+	)
+
 	once sync.Once
 )
 
 // RegisterMetrics registers metrics only once and logs using Zap
 func RegisterMetrics(logger *zap.Logger) {
 	once.Do(func() {
-		prometheus.MustRegister(httpRequestDuration, dbQueryDuration)
+		prometheus.MustRegister(httpRequestDuration, dbQueryDuration, apiKeyRequests, rateLimitRejections)
 		logger.Info("Metrics successfully registered")
 	})
 }
@@ -46,3 +64,13 @@ func ObserveHTTPRequestDuration(path, method, status string, seconds float64) {
 func ObserveDBQueryDuration(seconds float64) {
 	dbQueryDuration.Observe(seconds)
 }
+
+// CountAPIKeyRequest counts a request made with an API key
+func CountAPIKeyRequest(keyID, status string) {
+	apiKeyRequests.WithLabelValues(keyID, status).Inc()
+}
+
+// CountRateLimitRejection counts a request rejected by a rate limit
+func CountRateLimitRejection(route, client string) {
+	rateLimitRejections.WithLabelValues(route, client).Inc()
+}
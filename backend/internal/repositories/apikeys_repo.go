@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+const apiKeyColumns = "id, tenant_id, name, secret_hash, scopes, daily_quota, created_at, expires_at, revoked_at"
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (models.APIKey, error) {
+	var k models.APIKey
+	var scopes string
+	var expires, revoked sql.NullTime
+	if err := row.Scan(&k.ID, &k.Tenant, &k.Name, &k.SecretHash, &scopes, &k.DailyQuota, &k.CreatedAt, &expires, &revoked); err != nil {
+		return k, err
+	}
+	k.Scopes = strings.Split(scopes, ",")
+	if expires.Valid {
+		k.ExpiresAt = &expires.Time
+	}
+	if revoked.Valid {
+		k.RevokedAt = &revoked.Time
+	}
+	return k, nil
+}
+
+// GetAPIKey finds a key of any tenant.
+func (p *Postgres) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	k, err := scanAPIKey(p.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = $1", id))
+	return k, notFound(err)
+}
+
+func (p *Postgres) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := p.db.QueryContext(ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE tenant_id = $1 ORDER BY created_at, id",
+		tenant.FromContext(ctx),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// CreateAPIKey stores k for the tenant of ctx, whatever its Tenant says.
+func (p *Postgres) CreateAPIKey(ctx context.Context, k models.APIKey) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO api_keys (id, name, secret_hash, scopes, daily_quota, created_at, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		k.ID, k.Name, k.SecretHash, strings.Join(k.Scopes, ","), k.DailyQuota, k.CreatedAt, k.ExpiresAt, tenant.FromContext(ctx),
+	)
+	return err
+}
+
+func (p *Postgres) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	res, err := p.db.ExecContext(ctx,
+		"UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1 AND tenant_id = $3",
+		id, at, tenant.FromContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RecordAPIKeyUse increments the day's counter in one statement, so
+// concurrent requests are all counted.
+func (p *Postgres) RecordAPIKeyUse(ctx context.Context, id string, at time.Time) (int, error) {
+	var n int
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO api_key_usage (key_id, day, requests) VALUES ($1, $2, 1)
+		ON CONFLICT (key_id, day) DO UPDATE SET requests = api_key_usage.requests + 1
+		RETURNING requests`,
+		id, at.UTC().Format(time.DateOnly),
+	).Scan(&n)
+	return n, err
+}
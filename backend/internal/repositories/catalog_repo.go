@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+func (p *Postgres) ImportCatalog(ctx context.Context, plan models.CatalogPlan) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		for _, para := range plan.Paradigms {
+			if para.Revision == 0 {
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO paradigms (id, name, description, position, retired, revision, tenant_id) VALUES ($1, $2, $3, $4, $5, 1, $6)",
+					para.ID, para.Name, para.Description, para.Position, para.Retired, tenant.FromContext(ctx),
+				)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			res, err := tx.ExecContext(ctx,
+				`UPDATE paradigms SET name = $1, description = $2, position = $3, retired = $4, revision = revision + 1
+				WHERE id = $5 AND revision = $6 AND tenant_id = $7`,
+				para.Name, para.Description, para.Position, para.Retired, para.ID, para.Revision, tenant.FromContext(ctx),
+			)
+			if err := checkRevision(ctx, tx, res, err, "paradigms", "id = $1 AND tenant_id = $2", para.ID, tenant.FromContext(ctx)); err != nil {
+				return err
+			}
+		}
+
+		for _, q := range plan.Questions {
+			opts, conditions, curve, err := questionColumnValues(q)
+			if err != nil {
+				return err
+			}
+			if q.Revision == 0 {
+				_, err = tx.ExecContext(ctx,
+					`INSERT INTO questions (id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve, position, retired, revision, tenant_id)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, 1, $14)`,
+					q.ID, q.Paradigm, q.Text, q.Selector, opts, q.MinSelections, q.MaxSelections, q.Weight, q.Required, conditions, curve,
+					q.Position, q.Retired, tenant.FromContext(ctx),
+				)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			res, err := tx.ExecContext(ctx,
+				`UPDATE questions SET paradigm_id = $1, text = $2, selector = $3, options = $4, min_selections = $5, max_selections = $6,
+				weight = $7, required = $8, conditions = $9, curve = $10, position = $11, retired = $12, revision = revision + 1
+				WHERE id = $13 AND revision = $14 AND tenant_id = $15`,
+				q.Paradigm, q.Text, q.Selector, opts, q.MinSelections, q.MaxSelections, q.Weight, q.Required, conditions, curve,
+				q.Position, q.Retired, q.ID, q.Revision, tenant.FromContext(ctx),
+			)
+			if err := checkRevision(ctx, tx, res, err, "questions", "id = $1 AND tenant_id = $2", q.ID, tenant.FromContext(ctx)); err != nil {
+				return err
+			}
+		}
+
+		// Rows created with explicit ids must not collide with later inserts,
+		// of any tenant.
+		for _, table := range []string{"paradigms", "questions"} {
+			if _, err := tx.ExecContext(ctx,
+				"SELECT setval(pg_get_serial_sequence('"+table+"', 'id'), COALESCE((SELECT MAX(id) FROM "+table+"), 0) + 1, false)",
+			); err != nil {
+				return err
+			}
+		}
+
+		if plan.Tiers != nil {
+			for _, t := range plan.Tiers.Tiers {
+				_, err := tx.ExecContext(ctx,
+					"INSERT INTO policy_tiers (version, name, min_score, coverage_limit, description, tenant_id) VALUES ($1, $2, $3, $4, $5, $6)",
+					plan.Tiers.Version, t.Name, t.MinScore, t.CoverageLimit, t.Description, tenant.FromContext(ctx),
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (m *Memory) ImportCatalog(ctx context.Context, plan models.CatalogPlan) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+
+	paradigms := append([]models.Paradigm(nil), t.paradigms...)
+	for _, p := range plan.Paradigms {
+		i := indexWhere(len(paradigms), func(i int) bool { return paradigms[i].ID == p.ID })
+		switch {
+		case p.Revision == 0 && i < 0:
+			p.Revision = 1
+			paradigms = append(paradigms, p)
+		case i < 0:
+			return ErrNotFound
+		case paradigms[i].Revision != p.Revision:
+			return ErrConflict
+		default:
+			p.Revision++
+			paradigms[i] = p
+		}
+	}
+
+	questions := append([]models.Question(nil), t.questions...)
+	for _, q := range plan.Questions {
+		i := indexWhere(len(questions), func(i int) bool { return questions[i].ID == q.ID })
+		switch {
+		case q.Revision == 0 && i < 0:
+			q.Revision = 1
+			questions = append(questions, q)
+		case i < 0:
+			return ErrNotFound
+		case questions[i].Revision != q.Revision:
+			return ErrConflict
+		default:
+			q.Revision++
+			questions[i] = q
+		}
+	}
+
+	t.paradigms, t.questions = paradigms, questions
+	if plan.Tiers != nil {
+		t.tiers = *plan.Tiers
+	}
+	return nil
+}
+
+func indexWhere(n int, match func(i int) bool) int {
+	for i := 0; i < n; i++ {
+		if match(i) {
+			return i
+		}
+	}
+	return -1
+}
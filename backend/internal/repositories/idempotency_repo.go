@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+func (p *Postgres) GetIdempotencyRecord(ctx context.Context, key string) (models.IdempotencyRecord, error) {
+	var rec models.IdempotencyRecord
+	var response string
+	err := p.db.QueryRowContext(ctx,
+		"SELECT key, request_hash, status, response, submission_id, created_at FROM idempotency_keys WHERE key = $1 AND tenant_id = $2",
+		key, tenant.FromContext(ctx),
+	).Scan(&rec.Key, &rec.RequestHash, &rec.Status, &response, &rec.SubmissionID, &rec.CreatedAt)
+	if err != nil {
+		return models.IdempotencyRecord{}, notFound(err)
+	}
+	rec.Response = []byte(response)
+	return rec, nil
+}
+
+// SaveIdempotencyRecord waits for a concurrent transaction holding the same
+// key and reports ErrConflict if it committed.
+func (p *Postgres) SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error {
+	res, err := p.db.ExecContext(ctx,
+		`INSERT INTO idempotency_keys (key, request_hash, status, response, submission_id, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (tenant_id, key) DO NOTHING`,
+		rec.Key, rec.RequestHash, rec.Status, string(rec.Response), rec.SubmissionID, rec.CreatedAt, tenant.FromContext(ctx),
+	)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrConflict
+	}
+	return nil
+}
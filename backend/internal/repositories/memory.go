@@ -0,0 +1,646 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+// Memory implements Store in process memory. It is meant for tests and for
+// running the service locally without a database.
+type Memory struct {
+	sync.RWMutex
+	// txMu runs InTx calls one at a time.
+	txMu    sync.Mutex
+	rules   []models.UnderwritingRule
+	tenants map[string]*memoryTenant
+	// apiKeys are looked up across tenants, so they are kept here.
+	apiKeys     map[string]models.APIKey
+	apiKeyUsage map[string]int
+}
+
+// memoryTenant holds the data of one tenant.
+type memoryTenant struct {
+	info           models.Tenant
+	paradigms      []models.Paradigm
+	questions      []models.Question
+	questionnaires []models.Questionnaire
+	submissions    []models.Submission
+	sessions       map[string]models.AssessmentSession
+	idempotency    map[string]models.IdempotencyRecord
+	quotes         []models.Quote
+	tiers          models.TierTable
+	rates          models.RatingTable
+}
+
+// NewMemory returns an empty in-memory store with only the default tenant.
+func NewMemory() *Memory {
+	return &Memory{tenants: map[string]*memoryTenant{
+		tenant.Default: {info: models.Tenant{ID: tenant.Default, Name: "Default", CreatedAt: time.Now().UTC()}},
+	}}
+}
+
+// read returns the data of the tenant of ctx, empty if it has none. The
+// caller must hold the lock.
+func (m *Memory) read(ctx context.Context) *memoryTenant {
+	if t, ok := m.tenants[tenant.FromContext(ctx)]; ok {
+		return t
+	}
+	return &memoryTenant{}
+}
+
+// write returns the data of the tenant of ctx, or ErrUnknownTenant if it
+// was never created. The caller must hold the write lock.
+func (m *Memory) write(ctx context.Context) (*memoryTenant, error) {
+	id := tenant.FromContext(ctx)
+	t, ok := m.tenants[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+// SetParadigms replaces the default tenant's paradigms. Paradigms without a
+// revision start at revision 1.
+func (m *Memory) SetParadigms(ps []models.Paradigm) {
+	m.Lock()
+	defer m.Unlock()
+	t := m.tenants[tenant.Default]
+	t.paradigms = append([]models.Paradigm(nil), ps...)
+	for i := range t.paradigms {
+		if t.paradigms[i].Revision == 0 {
+			t.paradigms[i].Revision = 1
+		}
+	}
+}
+
+// SetQuestions replaces the default tenant's question catalog. Questions
+// without a revision start at revision 1.
+func (m *Memory) SetQuestions(qs []models.Question) {
+	m.Lock()
+	defer m.Unlock()
+	t := m.tenants[tenant.Default]
+	t.questions = append([]models.Question(nil), qs...)
+	for i := range t.questions {
+		if t.questions[i].Revision == 0 {
+			t.questions[i].Revision = 1
+		}
+	}
+}
+
+// SetScoringConfig replaces the default tenant's tiers and the shared rules.
+func (m *Memory) SetScoringConfig(tiers models.TierTable, rules []models.UnderwritingRule) {
+	m.Lock()
+	defer m.Unlock()
+	m.tenants[tenant.Default].tiers = tiers
+	m.rules = append([]models.UnderwritingRule(nil), rules...)
+}
+
+// SetRatingTable replaces the default tenant's rating table.
+func (m *Memory) SetRatingTable(t models.RatingTable) {
+	m.Lock()
+	defer m.Unlock()
+	m.tenants[tenant.Default].rates = t
+}
+
+func (m *Memory) ListParadigms(ctx context.Context) ([]models.Paradigm, error) {
+	all, _ := m.AllParadigms(ctx)
+	var ps []models.Paradigm
+	for _, p := range all {
+		if !p.Retired {
+			ps = append(ps, p)
+		}
+	}
+	return ps, nil
+}
+
+func (m *Memory) AllParadigms(ctx context.Context) ([]models.Paradigm, error) {
+	m.RLock()
+	ps := append([]models.Paradigm(nil), m.read(ctx).paradigms...)
+	m.RUnlock()
+	sort.SliceStable(ps, func(i, j int) bool {
+		if ps[i].Position != ps[j].Position {
+			return ps[i].Position < ps[j].Position
+		}
+		return ps[i].ID < ps[j].ID
+	})
+	return ps, nil
+}
+
+func (m *Memory) CreateParadigm(ctx context.Context, p models.Paradigm) (models.Paradigm, error) {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return p, err
+	}
+	for _, existing := range t.paradigms {
+		if existing.ID >= p.ID {
+			p.ID = existing.ID
+		}
+	}
+	p.ID++
+	p.Revision = 1
+	t.paradigms = append(t.paradigms, p)
+	return p, nil
+}
+
+func (m *Memory) UpdateParadigms(ctx context.Context, ps []models.Paradigm) ([]models.Paradigm, error) {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(ps))
+	for i, p := range ps {
+		idx[i] = -1
+		for j, existing := range t.paradigms {
+			if existing.ID == p.ID {
+				idx[i] = j
+			}
+		}
+		if idx[i] < 0 {
+			return nil, ErrNotFound
+		}
+		if t.paradigms[idx[i]].Revision != p.Revision {
+			return nil, ErrConflict
+		}
+	}
+	out := make([]models.Paradigm, len(ps))
+	for i, p := range ps {
+		p.Revision++
+		t.paradigms[idx[i]] = p
+		out[i] = p
+	}
+	return out, nil
+}
+
+func (m *Memory) ListQuestions(ctx context.Context) ([]models.Question, error) {
+	all, _ := m.AllQuestions(ctx)
+	var qs []models.Question
+	for _, q := range all {
+		if !q.Retired {
+			q.Position, q.Revision = 0, 0
+			qs = append(qs, q)
+		}
+	}
+	return qs, nil
+}
+
+func (m *Memory) AllQuestions(ctx context.Context) ([]models.Question, error) {
+	m.RLock()
+	qs := append([]models.Question(nil), m.read(ctx).questions...)
+	m.RUnlock()
+	sort.SliceStable(qs, func(i, j int) bool {
+		if qs[i].Position != qs[j].Position {
+			return qs[i].Position < qs[j].Position
+		}
+		return qs[i].ID < qs[j].ID
+	})
+	return qs, nil
+}
+
+func (m *Memory) CreateQuestion(ctx context.Context, q models.Question) (models.Question, error) {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return q, err
+	}
+	for _, existing := range t.questions {
+		if existing.ID >= q.ID {
+			q.ID = existing.ID
+		}
+	}
+	q.ID++
+	q.Revision = 1
+	t.questions = append(t.questions, q)
+	return q, nil
+}
+
+func (m *Memory) UpdateQuestions(ctx context.Context, qs []models.Question) ([]models.Question, error) {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return nil, err
+	}
+	idx := make([]int, len(qs))
+	for i, q := range qs {
+		idx[i] = -1
+		for j, existing := range t.questions {
+			if existing.ID == q.ID {
+				idx[i] = j
+			}
+		}
+		if idx[i] < 0 {
+			return nil, ErrNotFound
+		}
+		if t.questions[idx[i]].Revision != q.Revision {
+			return nil, ErrConflict
+		}
+	}
+	out := make([]models.Question, len(qs))
+	for i, q := range qs {
+		q.Revision++
+		t.questions[idx[i]] = q
+		out[i] = q
+	}
+	return out, nil
+}
+
+func (m *Memory) GetQuestionnaire(ctx context.Context, version int) (models.Questionnaire, error) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, qn := range m.read(ctx).questionnaires {
+		if qn.Version == version {
+			return qn, nil
+		}
+	}
+	return models.Questionnaire{}, ErrNotFound
+}
+
+func (m *Memory) CurrentQuestionnaire(ctx context.Context) (models.Questionnaire, error) {
+	m.RLock()
+	defer m.RUnlock()
+	for _, qn := range m.read(ctx).questionnaires {
+		if qn.Current {
+			return qn, nil
+		}
+	}
+	return models.Questionnaire{}, ErrNotFound
+}
+
+func (m *Memory) PublishQuestionnaire(ctx context.Context, qn models.Questionnaire) (models.Questionnaire, error) {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return qn, err
+	}
+	qn.Version = len(t.questionnaires) + 1
+	qn.Current = false
+	qn.PublishedAt = time.Now().UTC()
+	t.questionnaires = append(t.questionnaires, qn)
+	return qn, nil
+}
+
+func (m *Memory) SetCurrentQuestionnaire(ctx context.Context, version int) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, qn := range t.questionnaires {
+		if qn.Version == version {
+			found = true
+		}
+	}
+	if !found {
+		return ErrNotFound
+	}
+	for i := range t.questionnaires {
+		t.questionnaires[i].Current = t.questionnaires[i].Version == version
+	}
+	return nil
+}
+
+func (m *Memory) SaveSubmission(ctx context.Context, sub models.Submission) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	t.submissions = append(t.submissions, sub)
+	return nil
+}
+
+func (m *Memory) UserSubmissions(ctx context.Context, userID string) ([]models.Submission, error) {
+	m.RLock()
+	defer m.RUnlock()
+	var subs []models.Submission
+	for _, sub := range m.read(ctx).submissions {
+		if sub.UserID == userID {
+			subs = append(subs, sub)
+		}
+	}
+	sort.SliceStable(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs, nil
+}
+
+func (m *Memory) LatestSubmission(ctx context.Context, userID string) (models.Submission, error) {
+	subs, _ := m.UserSubmissions(ctx, userID)
+	if len(subs) == 0 {
+		return models.Submission{}, ErrNotFound
+	}
+	return subs[len(subs)-1], nil
+}
+
+func (m *Memory) LatestSubmissions(ctx context.Context) ([]models.Submission, error) {
+	m.RLock()
+	latest := map[string]models.Submission{}
+	for _, sub := range m.read(ctx).submissions {
+		if prev, ok := latest[sub.UserID]; !ok || !sub.CreatedAt.Before(prev.CreatedAt) {
+			latest[sub.UserID] = sub
+		}
+	}
+	m.RUnlock()
+
+	subs := make([]models.Submission, 0, len(latest))
+	for _, sub := range latest {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].UserID < subs[j].UserID })
+	return subs, nil
+}
+
+func (m *Memory) LatestResult(ctx context.Context, userID string) (models.Result, error) {
+	sub, err := m.LatestSubmission(ctx, userID)
+	return sub.Result, err
+}
+
+func (m *Memory) CreateSession(ctx context.Context, s models.AssessmentSession) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	if t.sessions == nil {
+		t.sessions = map[string]models.AssessmentSession{}
+	}
+	s.Answers = copyAnswers(s.Answers)
+	t.sessions[s.ID] = s
+	return nil
+}
+
+func (m *Memory) GetSession(ctx context.Context, id string) (models.AssessmentSession, error) {
+	m.RLock()
+	defer m.RUnlock()
+	s, ok := m.read(ctx).sessions[id]
+	if !ok {
+		return models.AssessmentSession{}, ErrNotFound
+	}
+	s.Answers = copyAnswers(s.Answers)
+	return s, nil
+}
+
+// draft returns the stored draft s was read from, or ErrNotFound or
+// ErrConflict. The caller must hold the lock.
+func (t *memoryTenant) draft(s models.AssessmentSession) (models.AssessmentSession, error) {
+	stored, ok := t.sessions[s.ID]
+	if !ok {
+		return stored, ErrNotFound
+	}
+	if stored.Revision != s.Revision || stored.Status != models.SessionDraft {
+		return stored, ErrConflict
+	}
+	return stored, nil
+}
+
+func (m *Memory) SaveSession(ctx context.Context, s models.AssessmentSession) (models.AssessmentSession, error) {
+	m.Lock()
+	defer m.Unlock()
+	t := m.read(ctx)
+	stored, err := t.draft(s)
+	if err != nil {
+		return s, err
+	}
+	stored.Answers = copyAnswers(s.Answers)
+	stored.UpdatedAt = s.UpdatedAt
+	stored.ExpiresAt = s.ExpiresAt
+	stored.Revision++
+	t.sessions[s.ID] = stored
+	s.Revision = stored.Revision
+	return s, nil
+}
+
+func (m *Memory) FinalizeSession(ctx context.Context, s models.AssessmentSession, sub models.Submission) (models.AssessmentSession, error) {
+	m.Lock()
+	defer m.Unlock()
+	t := m.read(ctx)
+	stored, err := t.draft(s)
+	if err != nil {
+		return s, err
+	}
+	t.submissions = append(t.submissions, sub)
+	stored.Status = models.SessionFinalized
+	stored.SubmissionID = sub.ID
+	stored.UpdatedAt = s.UpdatedAt
+	stored.Revision++
+	t.sessions[s.ID] = stored
+	s.Status, s.SubmissionID, s.Revision = stored.Status, stored.SubmissionID, stored.Revision
+	return s, nil
+}
+
+func (m *Memory) PurgeExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	m.Lock()
+	defer m.Unlock()
+	var n int64
+	for _, t := range m.tenants {
+		for id, s := range t.sessions {
+			if s.Status == models.SessionDraft && s.ExpiresAt.Before(now) {
+				delete(t.sessions, id)
+				n++
+			}
+		}
+	}
+	return n, nil
+}
+
+// InTx runs fn after any other InTx call has finished. Memory has no
+// rollback: changes fn made before failing are kept.
+func (m *Memory) InTx(ctx context.Context, fn func(Repositories) error) error {
+	m.txMu.Lock()
+	defer m.txMu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn(From(m))
+}
+
+func (m *Memory) GetIdempotencyRecord(ctx context.Context, key string) (models.IdempotencyRecord, error) {
+	m.RLock()
+	defer m.RUnlock()
+	rec, ok := m.read(ctx).idempotency[key]
+	if !ok {
+		return rec, ErrNotFound
+	}
+	return rec, nil
+}
+
+func (m *Memory) SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := t.idempotency[rec.Key]; ok {
+		return ErrConflict
+	}
+	if t.idempotency == nil {
+		t.idempotency = map[string]models.IdempotencyRecord{}
+	}
+	t.idempotency[rec.Key] = rec
+	return nil
+}
+
+func copyAnswers(answers map[int]interface{}) map[int]interface{} {
+	out := make(map[int]interface{}, len(answers))
+	for id, ans := range answers {
+		out[id] = ans
+	}
+	return out
+}
+
+func (m *Memory) SaveQuote(ctx context.Context, q models.Quote) error {
+	m.Lock()
+	defer m.Unlock()
+	t, err := m.write(ctx)
+	if err != nil {
+		return err
+	}
+	t.quotes = append(t.quotes, q)
+	return nil
+}
+
+func (m *Memory) CurrentTiers(ctx context.Context) (models.TierTable, error) {
+	m.RLock()
+	defer m.RUnlock()
+	t := m.read(ctx)
+	if len(t.tiers.Tiers) == 0 {
+		return models.TierTable{}, ErrNotFound
+	}
+	return t.tiers, nil
+}
+
+func (m *Memory) CurrentRatingTable(ctx context.Context) (models.RatingTable, error) {
+	m.RLock()
+	defer m.RUnlock()
+	t := m.read(ctx)
+	if len(t.rates.RevenueBands) == 0 {
+		return models.RatingTable{}, ErrNotFound
+	}
+	return t.rates, nil
+}
+
+func (m *Memory) ListRules(ctx context.Context) ([]models.UnderwritingRule, error) {
+	m.RLock()
+	defer m.RUnlock()
+	return append([]models.UnderwritingRule(nil), m.rules...), nil
+}
+
+func (m *Memory) GetTenant(ctx context.Context, id string) (models.Tenant, error) {
+	m.RLock()
+	defer m.RUnlock()
+	t, ok := m.tenants[id]
+	if !ok {
+		return models.Tenant{}, ErrNotFound
+	}
+	return t.info, nil
+}
+
+func (m *Memory) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	m.RLock()
+	var tenants []models.Tenant
+	for _, t := range m.tenants {
+		tenants = append(tenants, t.info)
+	}
+	m.RUnlock()
+	sort.Slice(tenants, func(i, j int) bool { return tenants[i].ID < tenants[j].ID })
+	return tenants, nil
+}
+
+func (m *Memory) CreateTenant(ctx context.Context, t models.Tenant) (models.Tenant, error) {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.tenants[t.ID]; ok {
+		return t, ErrConflict
+	}
+	t.CreatedAt = time.Now().UTC()
+	catalog := m.tenants[tenant.Default]
+	m.tenants[t.ID] = &memoryTenant{
+		info:      t,
+		paradigms: append([]models.Paradigm(nil), catalog.paradigms...),
+		questions: append([]models.Question(nil), catalog.questions...),
+	}
+	return t, nil
+}
+
+func (m *Memory) GetAPIKey(ctx context.Context, id string) (models.APIKey, error) {
+	m.RLock()
+	defer m.RUnlock()
+	k, ok := m.apiKeys[id]
+	if !ok {
+		return k, ErrNotFound
+	}
+	return k, nil
+}
+
+func (m *Memory) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	id := tenant.FromContext(ctx)
+	m.RLock()
+	var keys []models.APIKey
+	for _, k := range m.apiKeys {
+		if k.Tenant == id {
+			keys = append(keys, k)
+		}
+	}
+	m.RUnlock()
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID < keys[j].ID
+	})
+	return keys, nil
+}
+
+func (m *Memory) CreateAPIKey(ctx context.Context, k models.APIKey) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.apiKeys[k.ID]; ok {
+		return ErrConflict
+	}
+	if m.apiKeys == nil {
+		m.apiKeys = map[string]models.APIKey{}
+	}
+	k.Tenant = tenant.FromContext(ctx)
+	m.apiKeys[k.ID] = k
+	return nil
+}
+
+func (m *Memory) RevokeAPIKey(ctx context.Context, id string, at time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+	k, ok := m.apiKeys[id]
+	if !ok || k.Tenant != tenant.FromContext(ctx) {
+		return ErrNotFound
+	}
+	if k.RevokedAt == nil {
+		k.RevokedAt = &at
+		m.apiKeys[id] = k
+	}
+	return nil
+}
+
+func (m *Memory) RecordAPIKeyUse(ctx context.Context, id string, at time.Time) (int, error) {
+	m.Lock()
+	defer m.Unlock()
+	if m.apiKeyUsage == nil {
+		m.apiKeyUsage = map[string]int{}
+	}
+	day := id + "/" + at.UTC().Format(time.DateOnly)
+	m.apiKeyUsage[day]++
+	return m.apiKeyUsage[day], nil
+}
@@ -1,12 +1,23 @@
-package repostitories
+package repositories
 
 import (
-	"cyber-go/internal/models"
+	"context"
 	"database/sql"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
 )
 
-func GetAllParadigms(db *sql.DB) ([]models.Paradigm, error) {
-	rows, err := db.Query("SELECT id, name, description FROM paradigms")
+func (p *Postgres) ListParadigms(ctx context.Context) ([]models.Paradigm, error) {
+	return p.queryParadigms(ctx, "SELECT id, name, description, position, retired, revision FROM paradigms WHERE NOT retired AND tenant_id = $1 ORDER BY position, id", tenant.FromContext(ctx))
+}
+
+func (p *Postgres) AllParadigms(ctx context.Context) ([]models.Paradigm, error) {
+	return p.queryParadigms(ctx, "SELECT id, name, description, position, retired, revision FROM paradigms WHERE tenant_id = $1 ORDER BY position, id", tenant.FromContext(ctx))
+}
+
+func (p *Postgres) queryParadigms(ctx context.Context, query string, args ...interface{}) ([]models.Paradigm, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -16,10 +27,39 @@ func GetAllParadigms(db *sql.DB) ([]models.Paradigm, error) {
 
 	for rows.Next() {
 		var p models.Paradigm
-		if err := rows.Scan(&p.ID, &p.Name, &p.Description); err != nil {
+		if err := rows.Scan(&p.ID, &p.Name, &p.Description, &p.Position, &p.Retired, &p.Revision); err != nil {
 			return nil, err
 		}
 		paradigms = append(paradigms, p)
 	}
-	return paradigms, nil
+	return paradigms, rows.Err()
+}
+
+func (p *Postgres) CreateParadigm(ctx context.Context, para models.Paradigm) (models.Paradigm, error) {
+	para.Revision = 1
+	err := p.db.QueryRowContext(ctx,
+		"INSERT INTO paradigms (name, description, position, retired, revision, tenant_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id",
+		para.Name, para.Description, para.Position, para.Retired, para.Revision, tenant.FromContext(ctx),
+	).Scan(&para.ID)
+	return para, err
+}
+
+func (p *Postgres) UpdateParadigms(ctx context.Context, ps []models.Paradigm) ([]models.Paradigm, error) {
+	out := make([]models.Paradigm, len(ps))
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		for i, para := range ps {
+			res, err := tx.ExecContext(ctx,
+				`UPDATE paradigms SET name = $1, description = $2, position = $3, retired = $4, revision = revision + 1
+				WHERE id = $5 AND revision = $6 AND tenant_id = $7`,
+				para.Name, para.Description, para.Position, para.Retired, para.ID, para.Revision, tenant.FromContext(ctx),
+			)
+			if err := checkRevision(ctx, tx, res, err, "paradigms", "id = $1 AND tenant_id = $2", para.ID, tenant.FromContext(ctx)); err != nil {
+				return err
+			}
+			para.Revision++
+			out[i] = para
+		}
+		return nil
+	})
+	return out, err
 }
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Postgres implements Store on top of a database/sql connection.
+type Postgres struct {
+	conn *sql.DB
+	// db is conn, or the transaction of InTx.
+	db queryer
+	tx *sql.Tx
+}
+
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// NewPostgres returns a Postgres store using db.
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{conn: db, db: db}
+}
+
+// InTx runs fn with repositories bound to one transaction. It commits if fn
+// succeeds and rolls back otherwise, or when ctx is cancelled first.
+func (p *Postgres) InTx(ctx context.Context, fn func(Repositories) error) error {
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		return fn(From(&Postgres{conn: p.conn, db: tx, tx: tx}))
+	})
+}
+
+// notFound maps sql.ErrNoRows to ErrNotFound.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// inTx runs fn in a transaction, committing only if it succeeds.
+func (p *Postgres) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := p.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// checkRevision turns the result of a revision-guarded UPDATE into
+// ErrNotFound or ErrConflict when it matched no row. where selects the row
+// the UPDATE was meant for, ignoring its revision.
+func checkRevision(ctx context.Context, tx *sql.Tx, res sql.Result, err error, table, where string, args ...interface{}) error {
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n > 0 {
+		return err
+	}
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT TRUE FROM "+table+" WHERE "+where, args...).Scan(&exists); err != nil {
+		return notFound(err)
+	}
+	return ErrConflict
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+const questionnaireColumns = "SELECT id, label, is_current, published_at, catalog FROM questionnaire_versions"
+
+func scanQuestionnaire(row *sql.Row) (models.Questionnaire, error) {
+	var qn models.Questionnaire
+	var catalog string
+	if err := row.Scan(&qn.Version, &qn.Label, &qn.Current, &qn.PublishedAt, &catalog); err != nil {
+		return models.Questionnaire{}, notFound(err)
+	}
+	if err := json.Unmarshal([]byte(catalog), &qn.Questions); err != nil {
+		return models.Questionnaire{}, err
+	}
+	return qn, nil
+}
+
+func (p *Postgres) GetQuestionnaire(ctx context.Context, version int) (models.Questionnaire, error) {
+	return scanQuestionnaire(p.db.QueryRowContext(ctx, questionnaireColumns+" WHERE id = $1 AND tenant_id = $2", version, tenant.FromContext(ctx)))
+}
+
+func (p *Postgres) CurrentQuestionnaire(ctx context.Context) (models.Questionnaire, error) {
+	return scanQuestionnaire(p.db.QueryRowContext(ctx, questionnaireColumns+" WHERE is_current AND tenant_id = $1", tenant.FromContext(ctx)))
+}
+
+// PublishQuestionnaire stores a new version; the database assigns its
+// number and publication time.
+func (p *Postgres) PublishQuestionnaire(ctx context.Context, qn models.Questionnaire) (models.Questionnaire, error) {
+	catalog, err := json.Marshal(qn.Questions)
+	if err != nil {
+		return models.Questionnaire{}, err
+	}
+	err = p.db.QueryRowContext(ctx,
+		"INSERT INTO questionnaire_versions (label, catalog, tenant_id) VALUES ($1, $2, $3) RETURNING id, published_at",
+		qn.Label, string(catalog), tenant.FromContext(ctx),
+	).Scan(&qn.Version, &qn.PublishedAt)
+	qn.Current = false
+	return qn, err
+}
+
+func (p *Postgres) SetCurrentQuestionnaire(ctx context.Context, version int) error {
+	tenantID := tenant.FromContext(ctx)
+	return p.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, "UPDATE questionnaire_versions SET is_current = FALSE WHERE is_current AND tenant_id = $1", tenantID); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx, "UPDATE questionnaire_versions SET is_current = TRUE WHERE id = $1 AND tenant_id = $2", version, tenantID)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+const questionColumns = "id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve"
+
+func (p *Postgres) ListQuestions(ctx context.Context) ([]models.Question, error) {
+	return p.queryQuestions(ctx, "SELECT "+questionColumns+" FROM questions WHERE NOT retired AND tenant_id = $1 ORDER BY position, id", tenant.FromContext(ctx))
+}
+
+// AllQuestions also reads the admin columns, which the live catalog leaves
+// out of published questionnaires.
+func (p *Postgres) AllQuestions(ctx context.Context) ([]models.Question, error) {
+	return p.queryQuestions(ctx, "SELECT "+questionColumns+", position, retired, revision FROM questions WHERE tenant_id = $1 ORDER BY position, id", tenant.FromContext(ctx))
+}
+
+func (p *Postgres) queryQuestions(ctx context.Context, query string, args ...interface{}) ([]models.Question, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var questions []models.Question
+	for rows.Next() {
+		var q models.Question
+		var paradigmID int
+		var opts string
+		var conditions, curve sql.NullString
+		dest := []interface{}{&q.ID, &paradigmID, &q.Text, &q.Selector, &opts, &q.MinSelections, &q.MaxSelections,
+			&q.Weight, &q.Required, &conditions, &curve}
+		if len(cols) > len(dest) {
+			dest = append(dest, &q.Position, &q.Retired, &q.Revision)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if err := parseOptions(opts, &q); err != nil {
+			return nil, fmt.Errorf("question %d: invalid options: %w", q.ID, err)
+		}
+		q.Paradigm = fmt.Sprintf("%d", paradigmID)
+		if conditions.Valid && conditions.String != "" {
+			if err := json.Unmarshal([]byte(conditions.String), &q.Conditions); err != nil {
+				return nil, fmt.Errorf("question %d: invalid conditions: %w", q.ID, err)
+			}
+		}
+		if curve.Valid && curve.String != "" {
+			if err := json.Unmarshal([]byte(curve.String), &q.Curve); err != nil {
+				return nil, fmt.Errorf("question %d: invalid curve: %w", q.ID, err)
+			}
+		}
+		questions = append(questions, q)
+	}
+	return questions, rows.Err()
+}
+
+// parseOptions reads the options column, which holds either a JSON array of
+// scored options or a legacy comma-separated list of labels.
+func parseOptions(raw string, q *models.Question) error {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return nil
+	}
+	if !strings.HasPrefix(trimmed, "[") {
+		q.Options = strings.Split(raw, ",")
+		return nil
+	}
+	if err := json.Unmarshal([]byte(trimmed), &q.Choices); err != nil {
+		return err
+	}
+	q.Options = make([]string, len(q.Choices))
+	for i, c := range q.Choices {
+		q.Options[i] = c.Label
+	}
+	return nil
+}
+
+// questionColumnValues encodes the options, conditions and curve columns.
+// Questions without explicit choices keep the comma-separated format.
+func questionColumnValues(q models.Question) (opts string, conditions, curve sql.NullString, err error) {
+	opts = strings.Join(q.Options, ",")
+	if len(q.Choices) > 0 {
+		data, err := json.Marshal(q.Choices)
+		if err != nil {
+			return "", conditions, curve, err
+		}
+		opts = string(data)
+	}
+	if len(q.Conditions) > 0 {
+		data, err := json.Marshal(q.Conditions)
+		if err != nil {
+			return "", conditions, curve, err
+		}
+		conditions = sql.NullString{String: string(data), Valid: true}
+	}
+	if q.Curve != nil {
+		data, err := json.Marshal(q.Curve)
+		if err != nil {
+			return "", conditions, curve, err
+		}
+		curve = sql.NullString{String: string(data), Valid: true}
+	}
+	return opts, conditions, curve, nil
+}
+
+func (p *Postgres) CreateQuestion(ctx context.Context, q models.Question) (models.Question, error) {
+	opts, conditions, curve, err := questionColumnValues(q)
+	if err != nil {
+		return models.Question{}, err
+	}
+	q.Revision = 1
+	err = p.db.QueryRowContext(ctx,
+		`INSERT INTO questions (paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve, position, retired, revision, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) RETURNING id`,
+		q.Paradigm, q.Text, q.Selector, opts, q.MinSelections, q.MaxSelections, q.Weight, q.Required, conditions, curve,
+		q.Position, q.Retired, q.Revision, tenant.FromContext(ctx),
+	).Scan(&q.ID)
+	return q, err
+}
+
+func (p *Postgres) UpdateQuestions(ctx context.Context, qs []models.Question) ([]models.Question, error) {
+	out := make([]models.Question, len(qs))
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		for i, q := range qs {
+			opts, conditions, curve, err := questionColumnValues(q)
+			if err != nil {
+				return err
+			}
+			res, err := tx.ExecContext(ctx,
+				`UPDATE questions SET paradigm_id = $1, text = $2, selector = $3, options = $4, min_selections = $5, max_selections = $6,
+				weight = $7, required = $8, conditions = $9, curve = $10, position = $11, retired = $12, revision = revision + 1
+				WHERE id = $13 AND revision = $14 AND tenant_id = $15`,
+				q.Paradigm, q.Text, q.Selector, opts, q.MinSelections, q.MaxSelections, q.Weight, q.Required, conditions, curve,
+				q.Position, q.Retired, q.ID, q.Revision, tenant.FromContext(ctx),
+			)
+			if err := checkRevision(ctx, tx, res, err, "questions", "id = $1 AND tenant_id = $2", q.ID, tenant.FromContext(ctx)); err != nil {
+				return err
+			}
+			q.Revision++
+			out[i] = q
+		}
+		return nil
+	})
+	return out, err
+}
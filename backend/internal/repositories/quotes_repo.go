@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+func (p *Postgres) SaveQuote(ctx context.Context, q models.Quote) error {
+	details, err := json.Marshal(q)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx,
+		"INSERT INTO quotes (id, submission_id, user_id, premium, details, expires_at, tenant_id) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		q.ID, q.SubmissionID, q.UserID, q.Premium, string(details), q.ExpiresAt, tenant.FromContext(ctx),
+	)
+	return err
+}
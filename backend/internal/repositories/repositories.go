@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cyber-go/internal/models"
+)
+
+// ErrNotFound is returned when a lookup matches no rows.
+var ErrNotFound = errors.New("not found")
+
+// ErrConflict is returned when an update was based on a stale revision.
+var ErrConflict = errors.New("revision conflict")
+
+// ErrUnknownTenant is returned when writing for a tenant that was never
+// created.
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Paradigms, questions, questionnaire versions, tier and rating tables,
+// submissions, sessions, idempotency records and quotes belong to a tenant:
+// every method reading or writing them is scoped to the tenant of its context
+// (see tenant.FromContext), and rows of other tenants are never found.
+// Underwriting rules are shared by all tenants.
+
+// QuestionRepository reads the live question catalog: questions that are not
+// retired, in catalog order.
+type QuestionRepository interface {
+	ListQuestions(ctx context.Context) ([]models.Question, error)
+}
+
+// ParadigmRepository reads the active paradigms questions are grouped by.
+type ParadigmRepository interface {
+	ListParadigms(ctx context.Context) ([]models.Paradigm, error)
+}
+
+// CatalogRepository edits paradigms and questions, retired ones included.
+// Updates apply only if every row still has the Revision it was read at and
+// otherwise fail with ErrConflict, leaving all rows unchanged.
+type CatalogRepository interface {
+	AllParadigms(ctx context.Context) ([]models.Paradigm, error)
+	CreateParadigm(ctx context.Context, p models.Paradigm) (models.Paradigm, error)
+	UpdateParadigms(ctx context.Context, ps []models.Paradigm) ([]models.Paradigm, error)
+	AllQuestions(ctx context.Context) ([]models.Question, error)
+	CreateQuestion(ctx context.Context, q models.Question) (models.Question, error)
+	UpdateQuestions(ctx context.Context, qs []models.Question) ([]models.Question, error)
+	// ImportCatalog applies a plan in one transaction. Rows with a zero
+	// Revision are created with their own ids; tiers are stored for the
+	// tenant of ctx.
+	ImportCatalog(ctx context.Context, plan models.CatalogPlan) error
+}
+
+// QuestionnaireRepository stores published questionnaire versions.
+type QuestionnaireRepository interface {
+	GetQuestionnaire(ctx context.Context, version int) (models.Questionnaire, error)
+	CurrentQuestionnaire(ctx context.Context) (models.Questionnaire, error)
+	PublishQuestionnaire(ctx context.Context, qn models.Questionnaire) (models.Questionnaire, error)
+	SetCurrentQuestionnaire(ctx context.Context, version int) error
+}
+
+// SubmissionRepository stores scored submissions.
+type SubmissionRepository interface {
+	SaveSubmission(ctx context.Context, sub models.Submission) error
+	// UserSubmissions returns a user's submissions, oldest first.
+	UserSubmissions(ctx context.Context, userID string) ([]models.Submission, error)
+	// LatestSubmissions returns the most recent submission of every user.
+	LatestSubmissions(ctx context.Context) ([]models.Submission, error)
+	LatestSubmission(ctx context.Context, userID string) (models.Submission, error)
+}
+
+// SessionRepository stores draft assessment sessions. Saves and
+// finalization apply only to drafts still at the Revision they were read at
+// and otherwise fail with ErrConflict.
+type SessionRepository interface {
+	CreateSession(ctx context.Context, s models.AssessmentSession) error
+	GetSession(ctx context.Context, id string) (models.AssessmentSession, error)
+	SaveSession(ctx context.Context, s models.AssessmentSession) (models.AssessmentSession, error)
+	// FinalizeSession locks the draft and stores its submission in one
+	// transaction.
+	FinalizeSession(ctx context.Context, s models.AssessmentSession, sub models.Submission) (models.AssessmentSession, error)
+	// PurgeExpiredSessions deletes drafts of every tenant that expired
+	// before now.
+	PurgeExpiredSessions(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdempotencyRepository stores the responses of requests sent with an
+// Idempotency-Key.
+type IdempotencyRepository interface {
+	GetIdempotencyRecord(ctx context.Context, key string) (models.IdempotencyRecord, error)
+	// SaveIdempotencyRecord returns ErrConflict if the key is already
+	// stored.
+	SaveIdempotencyRecord(ctx context.Context, rec models.IdempotencyRecord) error
+}
+
+// TenantRepository stores the tenants data can be scoped to.
+type TenantRepository interface {
+	GetTenant(ctx context.Context, id string) (models.Tenant, error)
+	ListTenants(ctx context.Context) ([]models.Tenant, error)
+	// CreateTenant returns ErrConflict if the id is taken. The tenant
+	// starts with a copy of the default tenant's paradigms and questions.
+	CreateTenant(ctx context.Context, t models.Tenant) (models.Tenant, error)
+}
+
+// APIKeyRepository stores the API keys broker systems authenticate with.
+// Keys belong to the tenant of ctx, except that GetAPIKey finds a key of any
+// tenant: the key decides the tenant of the request it authenticates.
+type APIKeyRepository interface {
+	GetAPIKey(ctx context.Context, id string) (models.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	CreateAPIKey(ctx context.Context, k models.APIKey) error
+	// RevokeAPIKey returns ErrNotFound if the tenant has no such key.
+	// Revoking a revoked key keeps its first revocation time.
+	RevokeAPIKey(ctx context.Context, id string, at time.Time) error
+	// RecordAPIKeyUse counts a request made with a key on the UTC day of
+	// at and returns the number of requests made with it that day.
+	RecordAPIKeyUse(ctx context.Context, id string, at time.Time) (int, error)
+}
+
+// Transactor runs work that must succeed or fail as a whole.
+type Transactor interface {
+	// InTx runs fn with repositories sharing one transaction. Everything fn
+	// did is rolled back if it fails or ctx is cancelled.
+	InTx(ctx context.Context, fn func(Repositories) error) error
+}
+
+// ResultRepository reads the result of a user's latest assessment.
+type ResultRepository interface {
+	LatestResult(ctx context.Context, userID string) (models.Result, error)
+}
+
+// QuoteRepository stores priced quotes.
+type QuoteRepository interface {
+	SaveQuote(ctx context.Context, q models.Quote) error
+}
+
+// ScoringConfigRepository reads the policy tiers, rating tables and
+// underwriting rules.
+type ScoringConfigRepository interface {
+	// CurrentTiers returns ErrNotFound when the tenant has no tiers stored.
+	CurrentTiers(ctx context.Context) (models.TierTable, error)
+	// CurrentRatingTable returns ErrNotFound when the tenant has no rating
+	// table stored.
+	CurrentRatingTable(ctx context.Context) (models.RatingTable, error)
+	ListRules(ctx context.Context) ([]models.UnderwritingRule, error)
+}
+
+// Repositories bundles what the handlers need.
+type Repositories struct {
+	Questions      QuestionRepository
+	Paradigms      ParadigmRepository
+	Catalog        CatalogRepository
+	Questionnaires QuestionnaireRepository
+	Submissions    SubmissionRepository
+	Sessions       SessionRepository
+	Idempotency    IdempotencyRepository
+	Results        ResultRepository
+	Quotes         QuoteRepository
+	ScoringConfig  ScoringConfigRepository
+	Tenants        TenantRepository
+	APIKeys        APIKeyRepository
+	Tx             Transactor
+}
+
+// Store is implemented by every backend that provides all repositories.
+type Store interface {
+	QuestionRepository
+	ParadigmRepository
+	CatalogRepository
+	QuestionnaireRepository
+	SubmissionRepository
+	SessionRepository
+	IdempotencyRepository
+	ResultRepository
+	QuoteRepository
+	ScoringConfigRepository
+	TenantRepository
+	APIKeyRepository
+	Transactor
+}
+
+// From uses a single store for every repository.
+func From(s Store) Repositories {
+	return Repositories{
+		Questions:      s,
+		Paradigms:      s,
+		Catalog:        s,
+		Questionnaires: s,
+		Submissions:    s,
+		Sessions:       s,
+		Idempotency:    s,
+		Results:        s,
+		Quotes:         s,
+		ScoringConfig:  s,
+		Tenants:        s,
+		APIKeys:        s,
+		Tx:             s,
+	}
+}
@@ -0,0 +1,342 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
+)
+
+func TestPostgresListQuestionsParsesOptions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cols := []string{"id", "paradigm_id", "text", "selector", "options", "min_selections", "max_selections", "weight", "required", "conditions", "curve"}
+	mock.ExpectQuery("SELECT (.+) FROM questions").WillReturnRows(sqlmock.NewRows(cols).
+		AddRow(1, 2, "Do you use MFA?", "radio", "Yes,No", 0, 0, 10, true, nil, nil).
+		AddRow(2, 2, "Which controls?", "checkbox", `[{"label":"EDR","score":5},{"label":"None","exclusive":true}]`, 0, 0, 5, false,
+			`[{"questionId":1,"anyOf":["Yes"]}]`, nil))
+
+	qs, err := repositories.NewPostgres(db).ListQuestions(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(qs) != 2 || qs[0].Paradigm != "2" || len(qs[0].Options) != 2 {
+		t.Fatalf("unexpected questions: %+v", qs)
+	}
+	if len(qs[1].Choices) != 2 || qs[1].Options[1] != "None" || len(qs[1].Conditions) != 1 {
+		t.Errorf("expected JSON options and conditions to be parsed, got %+v", qs[1])
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresLatestSubmissionNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM submissions WHERE user_id = ").WithArgs("nobody", "default").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "questionnaire_version", "answers", "result", "created_at"}))
+
+	_, err = repositories.NewPostgres(db).LatestSubmission(context.Background(), "nobody")
+	if !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemorySubmissions(t *testing.T) {
+	ctx := context.Background()
+	m := repositories.NewMemory()
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, sub := range []models.Submission{
+		{ID: "a2", UserID: "alice", CreatedAt: start.Add(2 * time.Hour), Result: models.Result{TotalScore: 30}},
+		{ID: "a1", UserID: "alice", CreatedAt: start.Add(time.Hour), Result: models.Result{TotalScore: 10}},
+		{ID: "b1", UserID: "bob", CreatedAt: start},
+	} {
+		if err := m.SaveSubmission(ctx, sub); err != nil {
+			t.Fatalf("save %d: %v", i, err)
+		}
+	}
+
+	history, _ := m.UserSubmissions(ctx, "alice")
+	if len(history) != 2 || history[0].ID != "a1" {
+		t.Errorf("expected alice's submissions oldest first, got %+v", history)
+	}
+	res, err := m.LatestResult(ctx, "alice")
+	if err != nil || res.TotalScore != 30 {
+		t.Errorf("expected latest result of 30, got %+v (%v)", res, err)
+	}
+	latest, _ := m.LatestSubmissions(ctx)
+	if len(latest) != 2 || latest[0].ID != "a2" || latest[1].ID != "b1" {
+		t.Errorf("unexpected latest submissions: %+v", latest)
+	}
+	if _, err := m.LatestResult(ctx, "carol"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestMemoryQuestionnaires(t *testing.T) {
+	ctx := context.Background()
+	m := repositories.NewMemory()
+	if _, err := m.CurrentQuestionnaire(ctx); !errors.Is(err, repositories.ErrNotFound) {
+		t.Fatalf("expected no current questionnaire, got %v", err)
+	}
+
+	v1, _ := m.PublishQuestionnaire(ctx, models.Questionnaire{Label: "2026-Q1"})
+	v2, _ := m.PublishQuestionnaire(ctx, models.Questionnaire{Label: "2026-Q2"})
+	if v1.Version != 1 || v2.Version != 2 || v2.Current {
+		t.Fatalf("unexpected versions: %+v %+v", v1, v2)
+	}
+	if err := m.SetCurrentQuestionnaire(ctx, 3); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown version, got %v", err)
+	}
+	m.SetCurrentQuestionnaire(ctx, 1)
+	m.SetCurrentQuestionnaire(ctx, 2)
+	current, err := m.CurrentQuestionnaire(ctx)
+	if err != nil || current.Label != "2026-Q2" {
+		t.Errorf("expected 2026-Q2 to be current, got %+v (%v)", current, err)
+	}
+}
+
+func TestPostgresUpdateParadigmsConflict(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE paradigms SET (.+) WHERE id = (.+) AND revision = ").
+		WithArgs("Threat", "", 1, false, 1, 3, "default").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT TRUE FROM paradigms WHERE id = ").WithArgs(1, "default").
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err = repositories.NewPostgres(db).UpdateParadigms(context.Background(),
+		[]models.Paradigm{{ID: 1, Name: "Threat", Position: 1, Revision: 3}})
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresFinalizeSessionConflictRollsBack(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO submissions").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE assessment_sessions SET status = 'finalized'(.+) AND status = 'draft'").
+		WithArgs("sub-1", now, "draft-1", 2, "default").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery("SELECT TRUE FROM assessment_sessions WHERE id = ").WithArgs("draft-1", "default").
+		WillReturnRows(sqlmock.NewRows([]string{"bool"}).AddRow(true))
+	mock.ExpectRollback()
+
+	_, err = repositories.NewPostgres(db).FinalizeSession(context.Background(),
+		models.AssessmentSession{ID: "draft-1", Revision: 2, UpdatedAt: now},
+		models.Submission{ID: "sub-1", UserID: "12", CreatedAt: now})
+	if !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresScopesSessionsToTenant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("FROM assessment_sessions WHERE id = (.+) AND tenant_id = ").WithArgs("draft-1", "acme").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	ctx := tenant.WithID(context.Background(), "acme")
+	if _, err := repositories.NewPostgres(db).GetSession(ctx, "draft-1"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestMemoryIsolatesTenants(t *testing.T) {
+	m := repositories.NewMemory()
+	acme := tenant.WithID(context.Background(), "acme")
+	other := context.Background()
+	if _, err := m.CreateTenant(other, models.Tenant{ID: "acme", Name: "Acme"}); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	if _, err := m.CreateTenant(other, models.Tenant{ID: "acme", Name: "Acme"}); !errors.Is(err, repositories.ErrConflict) {
+		t.Errorf("expected ErrConflict for a taken id, got %v", err)
+	}
+
+	m.SaveSubmission(acme, models.Submission{ID: "s1", UserID: "alice"})
+	qn, _ := m.PublishQuestionnaire(acme, models.Questionnaire{Label: "acme-1"})
+	m.SetCurrentQuestionnaire(acme, qn.Version)
+	m.CreateSession(acme, models.AssessmentSession{ID: "d1", UserID: "alice", Status: models.SessionDraft, Revision: 1})
+	m.ImportCatalog(acme, models.CatalogPlan{Tiers: &models.TierTable{Version: 1, Tiers: []models.PolicyTier{{Name: "Acme Basic"}}}})
+
+	if _, err := m.LatestSubmission(other, "alice"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected another tenant's submission to be hidden, got %v", err)
+	}
+	if _, err := m.CurrentQuestionnaire(other); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected another tenant's questionnaire to be hidden, got %v", err)
+	}
+	if _, err := m.GetSession(other, "d1"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected another tenant's session to be hidden, got %v", err)
+	}
+	if _, err := m.CurrentTiers(other); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected another tenant's tiers to be hidden, got %v", err)
+	}
+
+	if sub, err := m.LatestSubmission(acme, "alice"); err != nil || sub.ID != "s1" {
+		t.Errorf("expected the tenant's own submission, got %+v (%v)", sub, err)
+	}
+	if tiers, err := m.CurrentTiers(acme); err != nil || tiers.Tiers[0].Name != "Acme Basic" {
+		t.Errorf("expected the tenant's own tiers, got %+v (%v)", tiers, err)
+	}
+	if tenants, _ := m.ListTenants(other); len(tenants) != 2 || tenants[0].ID != "acme" {
+		t.Errorf("expected acme and default, got %+v", tenants)
+	}
+}
+
+func TestMemoryTenantCatalogs(t *testing.T) {
+	m := repositories.NewMemory()
+	m.SetParadigms([]models.Paradigm{{ID: 1, Name: "Threat"}})
+	m.SetQuestions([]models.Question{{ID: 1, Paradigm: "1", Text: "Do you use MFA?"}})
+	if _, err := m.CreateTenant(context.Background(), models.Tenant{ID: "acme", Name: "Acme"}); err != nil {
+		t.Fatalf("failed to create tenant: %v", err)
+	}
+	acme := tenant.WithID(context.Background(), "acme")
+
+	qs, _ := m.ListQuestions(acme)
+	if len(qs) != 1 || qs[0].Text != "Do you use MFA?" {
+		t.Fatalf("expected a new tenant to start from the default catalog, got %+v", qs)
+	}
+	qs[0].Revision = 1
+	qs[0].Text = "Do you enforce MFA?"
+	if _, err := m.UpdateQuestions(acme, qs); err != nil {
+		t.Fatalf("failed to update question: %v", err)
+	}
+	if _, err := m.CreateParadigm(acme, models.Paradigm{Name: "Acme only"}); err != nil {
+		t.Fatalf("failed to create paradigm: %v", err)
+	}
+
+	if qs, _ := m.ListQuestions(context.Background()); qs[0].Text != "Do you use MFA?" {
+		t.Errorf("expected the default catalog to be unchanged, got %+v", qs)
+	}
+	if ps, _ := m.ListParadigms(context.Background()); len(ps) != 1 {
+		t.Errorf("expected another tenant's paradigm to be hidden, got %+v", ps)
+	}
+	if qs, _ := m.ListQuestions(acme); qs[0].Text != "Do you enforce MFA?" {
+		t.Errorf("expected the tenant's own question, got %+v", qs)
+	}
+}
+
+func TestMemoryRejectsUnknownTenants(t *testing.T) {
+	m := repositories.NewMemory()
+	ghost := tenant.WithID(context.Background(), "ghost")
+	if err := m.SaveSubmission(ghost, models.Submission{ID: "s1", UserID: "alice"}); !errors.Is(err, repositories.ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	if _, err := m.CreateQuestion(ghost, models.Question{Text: "Orphan?"}); !errors.Is(err, repositories.ErrUnknownTenant) {
+		t.Errorf("expected ErrUnknownTenant, got %v", err)
+	}
+	if _, err := m.GetTenant(context.Background(), "ghost"); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected the tenant not to have been created, got %v", err)
+	}
+}
+
+func TestPostgresRevokeAPIKeyOfAnotherTenant(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mock.ExpectExec("UPDATE api_keys SET revoked_at = (.+) WHERE id = (.+) AND tenant_id = ").
+		WithArgs("k1", now, "acme").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := tenant.WithID(context.Background(), "acme")
+	if err := repositories.NewPostgres(db).RevokeAPIKey(ctx, "k1", now); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresCurrentRatingTable(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	cols := []string{"version", "currency", "revenue_bands", "industry_multipliers", "worst_score_multiplier", "best_score_multiplier", "minimum_premium", "valid_for_days"}
+	mock.ExpectQuery("FROM rating_tables WHERE tenant_id = ").WithArgs("default").WillReturnRows(sqlmock.NewRows(cols).
+		AddRow(3, "EUR", `{"<1M":{"ratePerMillion":1000,"deductible":2000}}`, `{"other":1}`, 1.2, 0.9, 400, 14))
+	mock.ExpectQuery("FROM rating_tables WHERE tenant_id = ").WithArgs("default").WillReturnRows(sqlmock.NewRows(cols))
+
+	repo := repositories.NewPostgres(db)
+	table, err := repo.CurrentRatingTable(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if table.Version != 3 || table.RevenueBands["<1M"].Deductible != 2000 || table.ValidFor != 14*24*time.Hour {
+		t.Errorf("unexpected rating table: %+v", table)
+	}
+	if _, err := repo.CurrentRatingTable(context.Background()); !errors.Is(err, repositories.ErrNotFound) {
+		t.Errorf("expected ErrNotFound without a stored table, got %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
+
+func TestPostgresCreateTenantCopiesDefaultCatalog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO tenants").WithArgs("acme", "Acme").
+		WillReturnRows(sqlmock.NewRows([]string{"created_at"}).AddRow(time.Now()))
+	mock.ExpectExec("INSERT INTO paradigms (.+) FROM paradigms WHERE tenant_id = ").WithArgs("acme", "default").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO questions (.+) FROM questions WHERE tenant_id = ").WithArgs("acme", "default").
+		WillReturnResult(sqlmock.NewResult(0, 12))
+	mock.ExpectCommit()
+
+	if _, err := repositories.NewPostgres(db).CreateTenant(context.Background(), models.Tenant{ID: "acme", Name: "Acme"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+// CurrentTiers loads the tenant's highest version from the policy_tiers
+// table.
+func (p *Postgres) CurrentTiers(ctx context.Context) (models.TierTable, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT version, name, min_score, coverage_limit, description FROM policy_tiers
+		WHERE tenant_id = $1 AND version = (SELECT MAX(version) FROM policy_tiers WHERE tenant_id = $1) ORDER BY min_score`,
+		tenant.FromContext(ctx))
+	if err != nil {
+		return models.TierTable{}, err
+	}
+
+	defer rows.Close()
+	var table models.TierTable
+
+	for rows.Next() {
+		var t models.PolicyTier
+		if err := rows.Scan(&table.Version, &t.Name, &t.MinScore, &t.CoverageLimit, &t.Description); err != nil {
+			return models.TierTable{}, err
+		}
+		table.Tiers = append(table.Tiers, t)
+	}
+	if err := rows.Err(); err != nil {
+		return models.TierTable{}, err
+	}
+	if len(table.Tiers) == 0 {
+		return models.TierTable{}, ErrNotFound
+	}
+	return table, nil
+}
+
+// CurrentRatingTable loads the tenant's highest version from the
+// rating_tables table. revenue_bands and industry_multipliers hold JSON
+// objects keyed by band and industry.
+func (p *Postgres) CurrentRatingTable(ctx context.Context) (models.RatingTable, error) {
+	var t models.RatingTable
+	var bands, industries string
+	var validForDays int
+	err := p.db.QueryRowContext(ctx, `SELECT version, currency, revenue_bands, industry_multipliers,
+		worst_score_multiplier, best_score_multiplier, minimum_premium, valid_for_days
+		FROM rating_tables WHERE tenant_id = $1 ORDER BY version DESC LIMIT 1`,
+		tenant.FromContext(ctx),
+	).Scan(&t.Version, &t.Currency, &bands, &industries, &t.WorstScoreMultiplier, &t.BestScoreMultiplier, &t.MinimumPremium, &validForDays)
+	if err != nil {
+		return models.RatingTable{}, notFound(err)
+	}
+	if err := json.Unmarshal([]byte(bands), &t.RevenueBands); err != nil {
+		return models.RatingTable{}, fmt.Errorf("rating table %d: invalid revenue bands: %w", t.Version, err)
+	}
+	if err := json.Unmarshal([]byte(industries), &t.IndustryMultipliers); err != nil {
+		return models.RatingTable{}, fmt.Errorf("rating table %d: invalid industry multipliers: %w", t.Version, err)
+	}
+	t.ValidFor = time.Duration(validForDays) * 24 * time.Hour
+	return t, nil
+}
+
+// ListRules loads every rule from the underwriting_rules table. The
+// conditions column holds a JSON array of models.Condition.
+func (p *Postgres) ListRules(ctx context.Context) ([]models.UnderwritingRule, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT id, action, reason, conditions FROM underwriting_rules ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+	var rules []models.UnderwritingRule
+
+	for rows.Next() {
+		var r models.UnderwritingRule
+		var conditions string
+		if err := rows.Scan(&r.ID, &r.Action, &r.Reason, &conditions); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(conditions), &r.When); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid conditions: %w", r.ID, err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+const sessionColumns = "id, user_id, questionnaire_version, status, answers, revision, COALESCE(submission_id, ''), created_at, updated_at, expires_at"
+
+func (p *Postgres) CreateSession(ctx context.Context, s models.AssessmentSession) error {
+	answers, err := json.Marshal(s.Answers)
+	if err != nil {
+		return err
+	}
+	_, err = p.db.ExecContext(ctx,
+		`INSERT INTO assessment_sessions (id, user_id, questionnaire_version, status, answers, revision, created_at, updated_at, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		s.ID, s.UserID, s.QuestionnaireVersion, s.Status, string(answers), s.Revision, s.CreatedAt, s.UpdatedAt, s.ExpiresAt, tenant.FromContext(ctx),
+	)
+	return err
+}
+
+func (p *Postgres) GetSession(ctx context.Context, id string) (models.AssessmentSession, error) {
+	var s models.AssessmentSession
+	var answers string
+	err := p.db.QueryRowContext(ctx, "SELECT "+sessionColumns+" FROM assessment_sessions WHERE id = $1 AND tenant_id = $2", id, tenant.FromContext(ctx)).Scan(
+		&s.ID, &s.UserID, &s.QuestionnaireVersion, &s.Status, &answers, &s.Revision, &s.SubmissionID,
+		&s.CreatedAt, &s.UpdatedAt, &s.ExpiresAt,
+	)
+	if err != nil {
+		return models.AssessmentSession{}, notFound(err)
+	}
+	if err := json.Unmarshal([]byte(answers), &s.Answers); err != nil {
+		return models.AssessmentSession{}, fmt.Errorf("session %s: invalid answers: %w", s.ID, err)
+	}
+	return s, nil
+}
+
+func (p *Postgres) SaveSession(ctx context.Context, s models.AssessmentSession) (models.AssessmentSession, error) {
+	answers, err := json.Marshal(s.Answers)
+	if err != nil {
+		return s, err
+	}
+	tenantID := tenant.FromContext(ctx)
+	err = p.inTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE assessment_sessions SET answers = $1, updated_at = $2, expires_at = $3, revision = revision + 1
+			WHERE id = $4 AND revision = $5 AND status = 'draft' AND tenant_id = $6`,
+			string(answers), s.UpdatedAt, s.ExpiresAt, s.ID, s.Revision, tenantID,
+		)
+		return checkRevision(ctx, tx, res, err, "assessment_sessions", "id = $1 AND tenant_id = $2", s.ID, tenantID)
+	})
+	if err != nil {
+		return s, err
+	}
+	s.Revision++
+	return s, nil
+}
+
+func (p *Postgres) FinalizeSession(ctx context.Context, s models.AssessmentSession, sub models.Submission) (models.AssessmentSession, error) {
+	tenantID := tenant.FromContext(ctx)
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		// The submission goes first so the session can reference it.
+		if err := insertSubmission(ctx, tx, sub); err != nil {
+			return err
+		}
+		res, err := tx.ExecContext(ctx,
+			`UPDATE assessment_sessions SET status = 'finalized', submission_id = $1, updated_at = $2, revision = revision + 1
+			WHERE id = $3 AND revision = $4 AND status = 'draft' AND tenant_id = $5`,
+			sub.ID, s.UpdatedAt, s.ID, s.Revision, tenantID,
+		)
+		return checkRevision(ctx, tx, res, err, "assessment_sessions", "id = $1 AND tenant_id = $2", s.ID, tenantID)
+	})
+	if err != nil {
+		return s, err
+	}
+	s.Status = models.SessionFinalized
+	s.SubmissionID = sub.ID
+	s.Revision++
+	return s, nil
+}
+
+func (p *Postgres) PurgeExpiredSessions(ctx context.Context, now time.Time) (int64, error) {
+	res, err := p.db.ExecContext(ctx, "DELETE FROM assessment_sessions WHERE status = 'draft' AND expires_at < $1", now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
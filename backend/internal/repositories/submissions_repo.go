@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+const submissionColumns = "id, user_id, questionnaire_version, answers, result, created_at"
+
+func (p *Postgres) SaveSubmission(ctx context.Context, sub models.Submission) error {
+	return insertSubmission(ctx, p.db, sub)
+}
+
+func insertSubmission(ctx context.Context, db queryer, sub models.Submission) error {
+	answers, err := json.Marshal(sub.Answers)
+	if err != nil {
+		return err
+	}
+	reasons := sub.Result.Decision.Reasons
+	if reasons == nil {
+		reasons = []models.DecisionReason{}
+	}
+	decisionReasons, err := json.Marshal(reasons)
+	if err != nil {
+		return err
+	}
+	result, err := json.Marshal(sub.Result)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO submissions (id, user_id, questionnaire_version, answers, score, policy, decision, decision_reasons, result, created_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		sub.ID, sub.UserID, sub.QuestionnaireVersion, string(answers),
+		sub.Result.TotalScore, sub.Result.Policy, sub.Result.Decision.Outcome, string(decisionReasons), string(result), sub.CreatedAt, tenant.FromContext(ctx),
+	)
+	return err
+}
+
+func scanSubmission(scan func(dest ...interface{}) error) (models.Submission, error) {
+	var sub models.Submission
+	var answers, result string
+	if err := scan(&sub.ID, &sub.UserID, &sub.QuestionnaireVersion, &answers, &result, &sub.CreatedAt); err != nil {
+		return models.Submission{}, notFound(err)
+	}
+	if err := json.Unmarshal([]byte(answers), &sub.Answers); err != nil {
+		return models.Submission{}, fmt.Errorf("submission %s: invalid answers: %w", sub.ID, err)
+	}
+	if err := json.Unmarshal([]byte(result), &sub.Result); err != nil {
+		return models.Submission{}, fmt.Errorf("submission %s: invalid result: %w", sub.ID, err)
+	}
+	return sub, nil
+}
+
+func (p *Postgres) querySubmissions(ctx context.Context, query string, args ...interface{}) ([]models.Submission, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []models.Submission
+	for rows.Next() {
+		sub, err := scanSubmission(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (p *Postgres) LatestSubmission(ctx context.Context, userID string) (models.Submission, error) {
+	row := p.db.QueryRowContext(ctx, "SELECT "+submissionColumns+" FROM submissions WHERE user_id = $1 AND tenant_id = $2 ORDER BY created_at DESC LIMIT 1", userID, tenant.FromContext(ctx))
+	return scanSubmission(row.Scan)
+}
+
+func (p *Postgres) UserSubmissions(ctx context.Context, userID string) ([]models.Submission, error) {
+	return p.querySubmissions(ctx, "SELECT "+submissionColumns+" FROM submissions WHERE user_id = $1 AND tenant_id = $2 ORDER BY created_at", userID, tenant.FromContext(ctx))
+}
+
+func (p *Postgres) LatestSubmissions(ctx context.Context) ([]models.Submission, error) {
+	return p.querySubmissions(ctx, "SELECT DISTINCT ON (user_id) "+submissionColumns+" FROM submissions WHERE tenant_id = $1 ORDER BY user_id, created_at DESC", tenant.FromContext(ctx))
+}
+
+func (p *Postgres) LatestResult(ctx context.Context, userID string) (models.Result, error) {
+	sub, err := p.LatestSubmission(ctx, userID)
+	return sub.Result, err
+}
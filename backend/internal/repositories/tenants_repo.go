@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"cyber-go/internal/models"
+	"cyber-go/internal/tenant"
+)
+
+func (p *Postgres) GetTenant(ctx context.Context, id string) (models.Tenant, error) {
+	var t models.Tenant
+	err := p.db.QueryRowContext(ctx, "SELECT id, name, created_at FROM tenants WHERE id = $1", id).Scan(&t.ID, &t.Name, &t.CreatedAt)
+	return t, notFound(err)
+}
+
+func (p *Postgres) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT id, name, created_at FROM tenants ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []models.Tenant
+	for rows.Next() {
+		var t models.Tenant
+		if err := rows.Scan(&t.ID, &t.Name, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// CreateTenant stores t; the database sets its creation time. The tenant
+// starts with a copy of the default tenant's paradigms and questions.
+func (p *Postgres) CreateTenant(ctx context.Context, t models.Tenant) (models.Tenant, error) {
+	err := p.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx,
+			"INSERT INTO tenants (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING RETURNING created_at",
+			t.ID, t.Name,
+		).Scan(&t.CreatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrConflict
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO paradigms (tenant_id, id, name, description, position, retired, revision)
+			SELECT $1, id, name, description, position, retired, 1 FROM paradigms WHERE tenant_id = $2`,
+			t.ID, tenant.Default,
+		); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO questions (tenant_id, id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve, position, retired, revision)
+			SELECT $1, id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve, position, retired, 1
+			FROM questions WHERE tenant_id = $2`,
+			t.ID, tenant.Default,
+		)
+		return err
+	})
+	return t, err
+}
@@ -0,0 +1,24 @@
+// Package tenant carries the tenant a request is served for. Repositories
+// read it from the context to scope every query to one tenant's data.
+package tenant
+
+import "context"
+
+// Default is the tenant of requests that name none, and of all data stored
+// before tenants were introduced.
+const Default = "default"
+
+type contextKey struct{}
+
+// WithID returns a copy of ctx serving tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant ctx is served for, or Default.
+func FromContext(ctx context.Context) string {
+	if id, _ := ctx.Value(contextKey{}).(string); id != "" {
+		return id
+	}
+	return Default
+}
@@ -4,7 +4,8 @@ import (
 	"go.uber.org/zap"
 )
 
-var Logger *zap.Logger
+// Logger defaults to a no-op logger so packages can log before InitLogger runs
+var Logger = zap.NewNop()
 
 // InitLogger initializes the global Logger and returns a cleanup function
 func InitLogger() func() {
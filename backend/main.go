@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
+	"cyber-go/internal/commands"
+	"cyber-go/internal/controllers"
 	"cyber-go/internal/handlers"
 	"cyber-go/internal/middleware"
 	"cyber-go/internal/observability" // Ensure this import path is correct
+	"cyber-go/internal/repositories"
+	"cyber-go/internal/tenant"
 	"cyber-go/internal/util"
 	"cyber-go/pkg/db"
 
@@ -22,6 +31,14 @@ func main() {
 	cleanup := util.InitLogger()
 	defer cleanup()
 
+	// Subcommands run against the database and exit without serving
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// 2. Tracer
 	shutdown := observability.InitTracer()
 	defer shutdown()
@@ -30,58 +47,95 @@ func main() {
 	observability.RegisterMetrics(util.Logger)
 
 	// 4 Inidt DB (aftrer tracer, before app start)
-	myDB := db.Connect()
-	defer myDB.Close()
-	handlers.DB = myDB
+	repos, closeStore := openStore(os.Getenv("MIGRATE_ON_START") == "true")
+	defer closeStore()
+	h := handlers.New(repos)
+	if ttl := os.Getenv("DRAFT_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			log.Fatalf("invalid DRAFT_TTL %q", ttl)
+		}
+		h.SetDraftTTL(d)
+	}
+	if ttl := os.Getenv("CONFIG_CACHE_TTL"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d < 0 {
+			log.Fatalf("invalid CONFIG_CACHE_TTL %q", ttl)
+		}
+		h.SetConfigTTL(d)
+	}
+	go h.PurgeExpiredSessions(context.Background(), time.Hour)
+
+	// 5. Policy tiers, rating table and underwriting rules (validated before serving any scores)
+	loadScoringConfig(repos)
 
 	r := mux.NewRouter()
 	r.Use(middleware.ObservabilityMiddleware(util.Logger))
+	r.Use(middleware.AdminToken(os.Getenv("ADMIN_TOKEN")))
+	if auth := jwtAuthenticator(); auth != nil {
+		r.Use(middleware.Authenticate(auth))
+	}
+	r.Use(middleware.APIKeys(repos.APIKeys))
+	if limits := rateLimits(); len(limits) > 0 {
+		r.Use(middleware.RateLimit(middleware.NewMemoryRateLimitStore(), limits))
+	}
+	r.Use(middleware.APIKeyQuota(repos.APIKeys))
+	r.Use(tenantMiddleware(repos.Tenants))
 
 	r.Handle("/metrics", promhttp.Handler())
 
-	// REST endpoints
-	r.HandleFunc("/questions", handlers.GetQuestionsHandler).Methods("GET")
-	r.HandleFunc("/submit", handlers.SubmitHandler).Methods("POST")
-	r.HandleFunc("/result/{userID}", handlers.ResultHandler).Methods("GET")
+	// REST endpoints. Each needs the permission named, unless AUTH_DISABLED
+	// is set and the request carries no API key; applicants only reach their
+	// own results and assessments.
+	r.Handle("/questions", allow(middleware.PermReadQuestions, h.GetQuestionsHandler)).Methods("GET")
+	r.Handle("/submit", allow(middleware.PermSubmit, h.SubmitHandler)).Methods("POST")
+	r.Handle("/result/{userID}", middleware.RequireUser("userID")(http.HandlerFunc(h.ResultHandler))).Methods("GET")
+	r.Handle("/users/{userID}/assessments", middleware.RequireUser("userID")(http.HandlerFunc(h.AssessmentHistoryHandler))).Methods("GET")
+	r.Handle("/assessments", allow(middleware.PermSubmit, h.StartAssessmentHandler)).Methods("POST")
+	r.Handle("/assessments/{id}", allow(middleware.PermReadResults, h.GetAssessmentHandler)).Methods("GET")
+	r.Handle("/assessments/{id}/answers", allow(middleware.PermSubmit, h.SaveAnswersHandler)).Methods("PATCH")
+	r.Handle("/assessments/{id}/finalize", allow(middleware.PermSubmit, h.FinalizeAssessmentHandler)).Methods("POST")
+	r.Handle("/policies", allow(middleware.PermReadQuestions, h.GetPoliciesHandler)).Methods("GET")
+	r.Handle("/quotes", allow(middleware.PermQuote, h.QuoteHandler)).Methods("POST")
+	r.Handle("/questionnaires", middleware.RequireAdmin(http.HandlerFunc(h.PublishQuestionnaireHandler))).Methods("POST")
+	r.Handle("/questionnaires/{version}", allow(middleware.PermReadQuestions, h.GetQuestionnaireHandler)).Methods("GET")
+	r.Handle("/questionnaires/{version}/current", middleware.RequireAdmin(http.HandlerFunc(h.SetCurrentQuestionnaireHandler))).Methods("PUT")
+
+	// Admin endpoints (Authorization: Bearer $ADMIN_TOKEN). They manage the
+	// tenants and, for the tenant of the request, its catalog and rescoring;
+	// tenant admins are refused.
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(middleware.RequireGlobalAdmin)
+	admin.HandleFunc("/rescore", h.RescoreHandler).Methods("POST")
+	admin.HandleFunc("/paradigms", h.AdminParadigmsHandler).Methods("GET")
+	admin.HandleFunc("/paradigms", h.CreateParadigmHandler).Methods("POST")
+	admin.HandleFunc("/paradigms/order", h.ReorderParadigmsHandler).Methods("PUT")
+	admin.HandleFunc("/paradigms/{id:[0-9]+}", h.AdminParadigmHandler).Methods("GET")
+	admin.HandleFunc("/paradigms/{id:[0-9]+}", h.UpdateParadigmHandler).Methods("PUT")
+	admin.HandleFunc("/paradigms/{id:[0-9]+}", h.RetireParadigmHandler).Methods("DELETE")
+	admin.HandleFunc("/questions", h.AdminQuestionsHandler).Methods("GET")
+	admin.HandleFunc("/questions", h.CreateQuestionHandler).Methods("POST")
+	admin.HandleFunc("/questions/order", h.ReorderQuestionsHandler).Methods("PUT")
+	admin.HandleFunc("/questions/{id:[0-9]+}", h.AdminQuestionHandler).Methods("GET")
+	admin.HandleFunc("/questions/{id:[0-9]+}", h.UpdateQuestionHandler).Methods("PUT")
+	admin.HandleFunc("/questions/{id:[0-9]+}", h.RetireQuestionHandler).Methods("DELETE")
+	admin.HandleFunc("/questions/{id:[0-9]+}/paradigm", h.AssignQuestionHandler).Methods("PUT")
+	admin.HandleFunc("/tenants", h.AdminTenantsHandler).Methods("GET")
+	admin.HandleFunc("/tenants", h.CreateTenantHandler).Methods("POST")
 
 	// Basic HTTP server (placeholder for GraphQL)
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Cyber Service is running"))
 	})
 	// Rest endpoint
-	r.HandleFunc("/paradigms", handlers.GetParadigmsHandler(func() ([]map[string]interface{}, error) {
-		rows, err := handlers.DB.Query("SELECT * FROM paradigms")
-		if err != nil {
-			return nil, err
-		}
-		defer rows.Close()
-
-		columns, err := rows.Columns()
-		if err != nil {
-			return nil, err
-		}
-
-		var results []map[string]interface{}
-		for rows.Next() {
-			values := make([]interface{}, len(columns))
-			valuePtrs := make([]interface{}, len(columns))
-			for i := range values {
-				valuePtrs[i] = &values[i]
-			}
-			if err := rows.Scan(valuePtrs...); err != nil {
-				return nil, err
-			}
-			entry := make(map[string]interface{})
-			for i, col := range columns {
-				entry[col] = values[i]
-			}
-			results = append(results, entry)
-		}
-		return results, nil
-	}))
+	r.Handle("/paradigms", allow(middleware.PermReadQuestions, h.GetParadigmsHandler)).Methods("GET")
 
 	// GraphQL endpoint
-	r.Handle("/graphql", handlers.GraphqlHandler(handlers.Schema))
+	schema, err := h.Schema()
+	if err != nil {
+		log.Fatalf("invalid GraphQL schema: %v", err)
+	}
+	r.Handle("/graphql", middleware.Require(middleware.PermReadQuestions)(h.GraphqlHandler(schema, graphqlConfig())))
 
 	middleware.MiddlewareScraper(30 * time.Second)
 
@@ -89,3 +143,213 @@ func main() {
 	log.Println("Server started at :8080")
 	log.Fatal(http.ListenAndServe(":8080", r))
 }
+
+// openStore returns the repositories to serve from. STORAGE=memory runs
+// without a database, which is handy for local development. With migrate
+// set, pending schema migrations are applied first.
+func openStore(migrate bool) (repositories.Repositories, func()) {
+	if os.Getenv("STORAGE") == "memory" {
+		util.Logger.Warn("using in-memory storage, data is lost on exit")
+		return repositories.From(repositories.NewMemory()), func() {}
+	}
+	conn := db.Connect()
+	if migrate {
+		if err := commands.Migrate(conn, []string{"up"}, os.Stdout); err != nil {
+			log.Fatalf("failed to migrate database: %v", err)
+		}
+	}
+	return repositories.From(repositories.NewPostgres(conn)), func() { conn.Close() }
+}
+
+// loadScoringConfig loads the policy tiers, rating table and underwriting
+// rules used to score and price, exiting if any is invalid, the live question
+// catalog is inconsistent, or a rule does not match it. Admin edits and
+// catalog imports are checked as they are made, so requests need not check
+// the catalog again.
+func loadScoringConfig(repos repositories.Repositories) {
+	ctx := context.Background()
+	repo := repos.ScoringConfig
+	tiers, err := repo.CurrentTiers(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		util.Logger.Warn("policy_tiers table is empty, using default tiers")
+	} else if err != nil {
+		log.Fatalf("failed to load policy tiers: %v", err)
+	} else if err := controllers.SetTierTable(tiers); err != nil {
+		log.Fatalf("invalid policy tiers version %d: %v", tiers.Version, err)
+	}
+
+	rates, err := repo.CurrentRatingTable(ctx)
+	if errors.Is(err, repositories.ErrNotFound) {
+		util.Logger.Warn("rating_tables table is empty, using default rating table")
+	} else if err != nil {
+		log.Fatalf("failed to load rating table: %v", err)
+	} else if err := controllers.SetRatingTable(rates); err != nil {
+		log.Fatalf("invalid rating table version %d: %v", rates.Version, err)
+	}
+
+	rules, err := repo.ListRules(ctx)
+	if err != nil {
+		log.Fatalf("failed to load underwriting rules: %v", err)
+	}
+	questions, err := repos.Questions.ListQuestions(ctx)
+	if err != nil {
+		log.Fatalf("failed to load questions: %v", err)
+	}
+	if err := controllers.ValidateCatalog(questions); err != nil {
+		log.Fatalf("invalid question catalog: %v", err)
+	}
+	if err := controllers.ValidateRulesAgainst(rules, questions); err != nil {
+		log.Fatalf("invalid underwriting rules: %v", err)
+	}
+	if err := controllers.SetRules(rules); err != nil {
+		log.Fatalf("invalid underwriting rules: %v", err)
+	}
+}
+
+// tenantMiddleware resolves the tenant of each request from its bearer token,
+// using the token=tenant pairs of TENANT_TOKENS, the tenant claim of a JWT
+// or the tenant of an API key, or else from the X-Tenant-ID header.
+func tenantMiddleware(tenants repositories.TenantRepository) func(http.Handler) http.Handler {
+	tokens, err := middleware.ParseTenantTokens(os.Getenv("TENANT_TOKENS"))
+	if err != nil {
+		log.Fatalf("invalid TENANT_TOKENS: %v", err)
+	}
+	return middleware.Tenant(tenants, middleware.TenantFromTokens(tokens), middleware.TenantFromPrincipal, middleware.TenantFromHeader)
+}
+
+// rateLimits reads the per-route limits of RATE_LIMITS, such as
+// "/submit=30/m:5,default=600/m". Without it, requests are not limited.
+func rateLimits() map[string]middleware.Limit {
+	limits, err := middleware.ParseRateLimits(os.Getenv("RATE_LIMITS"))
+	if err != nil {
+		log.Fatalf("invalid RATE_LIMITS: %v", err)
+	}
+	return limits
+}
+
+// allow guards a route with a permission; see middleware.Require.
+func allow(perm middleware.Permission, h http.HandlerFunc) http.Handler {
+	return middleware.Require(perm)(h)
+}
+
+// jwtAuthenticator loads the OpenID Connect issuers listed in the JSON file
+// named by OIDC_ISSUERS. Without it the server refuses to start, unless
+// AUTH_DISABLED=true opts out of authentication, as for local development.
+func jwtAuthenticator() *middleware.JWTAuthenticator {
+	path := os.Getenv("OIDC_ISSUERS")
+	if path == "" {
+		if os.Getenv("AUTH_DISABLED") != "true" {
+			log.Fatal("OIDC_ISSUERS is not set; set AUTH_DISABLED=true to serve without authentication")
+		}
+		util.Logger.Warn("AUTH_DISABLED is set, endpoints are not authenticated")
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("failed to open OIDC issuers: %v", err)
+	}
+	defer f.Close()
+	issuers, err := middleware.LoadIssuers(f)
+	if err != nil {
+		log.Fatalf("invalid OIDC issuers %s: %v", path, err)
+	}
+	auth, err := middleware.NewJWTAuthenticator(issuers)
+	if err != nil {
+		log.Fatalf("invalid OIDC issuers %s: %v", path, err)
+	}
+	return auth
+}
+
+// commandContext returns the context subcommands run in, scoped to the
+// tenant named by TENANT.
+func commandContext(tenants repositories.TenantRepository) (context.Context, error) {
+	ctx := context.Background()
+	id := os.Getenv("TENANT")
+	if id == "" {
+		return ctx, nil
+	}
+	if _, err := tenants.GetTenant(ctx, id); err != nil {
+		return ctx, fmt.Errorf("tenant %q: %w", id, err)
+	}
+	return tenant.WithID(ctx, id), nil
+}
+
+// graphqlConfig reads the GraphQL limits from the environment:
+// GRAPHQL_MAX_DEPTH and GRAPHQL_MAX_COMPLEXITY (0 disables a limit),
+// GRAPHQL_PERSISTED_QUERIES, a JSON file of allowed queries, and
+// GRAPHQL_PERSISTED_ONLY=true to run nothing else, as in production.
+func graphqlConfig() handlers.GraphqlConfig {
+	cfg := handlers.GraphqlConfig{
+		MaxDepth:      envInt("GRAPHQL_MAX_DEPTH", 10),
+		MaxComplexity: envInt("GRAPHQL_MAX_COMPLEXITY", 5000),
+		PersistedOnly: os.Getenv("GRAPHQL_PERSISTED_ONLY") == "true",
+		Pretty:        true,
+	}
+	if path := os.Getenv("GRAPHQL_PERSISTED_QUERIES"); path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("failed to open persisted queries: %v", err)
+		}
+		defer f.Close()
+		if cfg.PersistedQueries, err = handlers.LoadPersistedQueries(f); err != nil {
+			log.Fatalf("invalid persisted queries %s: %v", path, err)
+		}
+	}
+	if cfg.PersistedOnly && len(cfg.PersistedQueries) == 0 {
+		log.Fatalf("GRAPHQL_PERSISTED_ONLY is set but GRAPHQL_PERSISTED_QUERIES lists no queries")
+	}
+	return cfg
+}
+
+func envInt(name string, fallback int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		log.Fatalf("invalid %s: %v", name, err)
+	}
+	return n
+}
+
+// runCommand runs a `cyber-go <command>` subcommand.
+func runCommand(name string, args []string) error {
+	switch name {
+	case "migrate":
+		conn := db.Connect()
+		defer conn.Close()
+		return commands.Migrate(conn, args, os.Stdout)
+	case "catalog":
+		repos, closeStore := openStore(false)
+		defer closeStore()
+		ctx, err := commandContext(repos.Tenants)
+		if err != nil {
+			return err
+		}
+		return commands.Catalog(ctx, handlers.New(repos), args, os.Stdout)
+	case "rescore":
+		repos, closeStore := openStore(false)
+		defer closeStore()
+		ctx, err := commandContext(repos.Tenants)
+		if err != nil {
+			return err
+		}
+		loadScoringConfig(repos)
+		return commands.Rescore(ctx, handlers.New(repos), args, os.Stdout)
+	case "tenant":
+		repos, closeStore := openStore(false)
+		defer closeStore()
+		return commands.Tenant(context.Background(), handlers.New(repos), args, os.Stdout)
+	case "apikey":
+		repos, closeStore := openStore(false)
+		defer closeStore()
+		ctx, err := commandContext(repos.Tenants)
+		if err != nil {
+			return err
+		}
+		return commands.APIKey(ctx, handlers.New(repos), args, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
@@ -26,7 +26,7 @@ func Connect() *sql.DB {
 		os.Getenv("DB_PASSWORD"),
 		os.Getenv("DB_NAME"),
 	)
-	fmt.Println(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
+	log.Println(os.Getenv("DB_HOST"), os.Getenv("DB_PORT"))
 	var err error
 	db, err = sql.Open("postgres", connStr)
 	if err != nil {
@@ -37,6 +37,6 @@ func Connect() *sql.DB {
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Println("Connected to PostgreSQL!")
+	log.Println("Connected to PostgreSQL!")
 	return db
 }
@@ -0,0 +1,30 @@
+CREATE TABLE paradigms (
+    id          SERIAL PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT ''
+);
+
+-- options holds a JSON array of scored options, or a legacy comma-separated
+-- list of labels. conditions and curve hold JSON and may be NULL.
+CREATE TABLE questions (
+    id             SERIAL PRIMARY KEY,
+    paradigm_id    INTEGER NOT NULL REFERENCES paradigms (id),
+    text           TEXT NOT NULL,
+    selector       TEXT NOT NULL,
+    options        TEXT NOT NULL DEFAULT '',
+    min_selections INTEGER NOT NULL DEFAULT 0,
+    max_selections INTEGER NOT NULL DEFAULT 0,
+    weight         INTEGER NOT NULL DEFAULT 0,
+    required       BOOLEAN NOT NULL DEFAULT FALSE,
+    conditions     TEXT,
+    curve          TEXT
+);
+
+-- results is the original per-submission score table, kept for existing data.
+CREATE TABLE results (
+    id         SERIAL PRIMARY KEY,
+    user_id    TEXT NOT NULL,
+    score      INTEGER NOT NULL,
+    policy     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
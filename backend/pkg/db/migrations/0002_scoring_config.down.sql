@@ -0,0 +1,2 @@
+DROP TABLE underwriting_rules;
+DROP TABLE policy_tiers;
@@ -0,0 +1,17 @@
+-- Each version of the tier table is a set of rows; the highest version is used.
+CREATE TABLE policy_tiers (
+    version        INTEGER NOT NULL,
+    name           TEXT NOT NULL,
+    min_score      INTEGER NOT NULL,
+    coverage_limit BIGINT NOT NULL,
+    description    TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (version, name)
+);
+
+-- conditions holds a JSON array of {"questionId", "anyOf"} objects.
+CREATE TABLE underwriting_rules (
+    id         TEXT PRIMARY KEY,
+    action     TEXT NOT NULL CHECK (action IN ('refer', 'decline')),
+    reason     TEXT NOT NULL,
+    conditions TEXT NOT NULL
+);
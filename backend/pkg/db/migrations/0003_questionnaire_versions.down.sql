@@ -0,0 +1 @@
+DROP TABLE questionnaire_versions;
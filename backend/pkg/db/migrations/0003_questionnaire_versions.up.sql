@@ -0,0 +1,10 @@
+-- catalog is the JSON snapshot of the questions at publication time.
+CREATE TABLE questionnaire_versions (
+    id           SERIAL PRIMARY KEY,
+    label        TEXT NOT NULL,
+    is_current   BOOLEAN NOT NULL DEFAULT FALSE,
+    published_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    catalog      TEXT NOT NULL
+);
+
+CREATE UNIQUE INDEX questionnaire_versions_current ON questionnaire_versions (is_current) WHERE is_current;
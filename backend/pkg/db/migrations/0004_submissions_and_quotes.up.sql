@@ -0,0 +1,23 @@
+-- questionnaire_version 0 means the unpublished live questions table.
+CREATE TABLE submissions (
+    id                    TEXT PRIMARY KEY,
+    user_id               TEXT NOT NULL,
+    questionnaire_version INTEGER NOT NULL,
+    answers               TEXT NOT NULL,
+    score                 INTEGER NOT NULL,
+    policy                TEXT NOT NULL,
+    decision              TEXT NOT NULL,
+    result                TEXT NOT NULL,
+    created_at            TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX submissions_user_created ON submissions (user_id, created_at);
+
+CREATE TABLE quotes (
+    id            TEXT PRIMARY KEY,
+    submission_id TEXT NOT NULL REFERENCES submissions (id),
+    user_id       TEXT NOT NULL,
+    premium       NUMERIC(12, 2) NOT NULL,
+    details       TEXT NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL
+);
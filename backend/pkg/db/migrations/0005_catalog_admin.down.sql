@@ -0,0 +1,2 @@
+ALTER TABLE questions DROP COLUMN position, DROP COLUMN retired, DROP COLUMN revision;
+ALTER TABLE paradigms DROP COLUMN position, DROP COLUMN retired, DROP COLUMN revision;
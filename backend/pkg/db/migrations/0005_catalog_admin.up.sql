@@ -0,0 +1,13 @@
+ALTER TABLE paradigms
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN retired  BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+ALTER TABLE questions
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN retired  BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN revision INTEGER NOT NULL DEFAULT 1;
+
+-- Keep the existing order, which was by id.
+UPDATE paradigms SET position = id;
+UPDATE questions SET position = id;
@@ -0,0 +1 @@
+DROP TABLE assessment_sessions;
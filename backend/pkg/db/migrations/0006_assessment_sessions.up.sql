@@ -0,0 +1,16 @@
+-- Draft assessments saved across sittings. A draft is scored into a
+-- submission when finalized; drafts left alone past expires_at are purged.
+CREATE TABLE assessment_sessions (
+    id                    TEXT PRIMARY KEY,
+    user_id               TEXT NOT NULL,
+    questionnaire_version INTEGER NOT NULL,
+    status                TEXT NOT NULL DEFAULT 'draft',
+    answers               TEXT NOT NULL,
+    revision              INTEGER NOT NULL DEFAULT 1,
+    submission_id         TEXT REFERENCES submissions (id),
+    created_at            TIMESTAMPTZ NOT NULL,
+    updated_at            TIMESTAMPTZ NOT NULL,
+    expires_at            TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX assessment_sessions_expiry ON assessment_sessions (expires_at) WHERE status = 'draft';
@@ -0,0 +1,10 @@
+-- Responses of POST /submit requests sent with an Idempotency-Key, stored
+-- in the same transaction as their submission and replayed for retries.
+CREATE TABLE idempotency_keys (
+    key           TEXT PRIMARY KEY,
+    request_hash  TEXT NOT NULL,
+    status        INTEGER NOT NULL,
+    response      TEXT NOT NULL,
+    submission_id TEXT NOT NULL REFERENCES submissions (id),
+    created_at    TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,28 @@
+-- Only the default tenant's data fits the single namespace.
+DELETE FROM idempotency_keys WHERE tenant_id <> 'default';
+DELETE FROM quotes WHERE tenant_id <> 'default';
+DELETE FROM assessment_sessions WHERE tenant_id <> 'default';
+DELETE FROM submissions WHERE tenant_id <> 'default';
+DELETE FROM policy_tiers WHERE tenant_id <> 'default';
+DELETE FROM questionnaire_versions WHERE tenant_id <> 'default';
+
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys DROP COLUMN tenant_id;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (key);
+
+ALTER TABLE assessment_sessions DROP COLUMN tenant_id;
+ALTER TABLE quotes DROP COLUMN tenant_id;
+
+DROP INDEX submissions_user_created;
+ALTER TABLE submissions DROP COLUMN tenant_id;
+CREATE INDEX submissions_user_created ON submissions (user_id, created_at);
+
+ALTER TABLE policy_tiers DROP CONSTRAINT policy_tiers_pkey;
+ALTER TABLE policy_tiers DROP COLUMN tenant_id;
+ALTER TABLE policy_tiers ADD PRIMARY KEY (version, name);
+
+DROP INDEX questionnaire_versions_current;
+ALTER TABLE questionnaire_versions DROP COLUMN tenant_id;
+CREATE UNIQUE INDEX questionnaire_versions_current ON questionnaire_versions (is_current) WHERE is_current;
+
+DROP TABLE tenants;
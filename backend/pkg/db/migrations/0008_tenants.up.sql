@@ -0,0 +1,38 @@
+-- Tenants the service is white-labelled for. Questionnaire versions, tier
+-- tables and everything applicants submit belong to one tenant; the question
+-- catalog and underwriting rules stay shared. Existing rows move to the
+-- default tenant.
+CREATE TABLE tenants (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO tenants (id, name) VALUES ('default', 'Default');
+
+ALTER TABLE questionnaire_versions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE questionnaire_versions ALTER COLUMN tenant_id DROP DEFAULT;
+DROP INDEX questionnaire_versions_current;
+CREATE UNIQUE INDEX questionnaire_versions_current ON questionnaire_versions (tenant_id) WHERE is_current;
+
+ALTER TABLE policy_tiers ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE policy_tiers ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE policy_tiers DROP CONSTRAINT policy_tiers_pkey;
+ALTER TABLE policy_tiers ADD PRIMARY KEY (tenant_id, version, name);
+
+ALTER TABLE submissions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE submissions ALTER COLUMN tenant_id DROP DEFAULT;
+DROP INDEX submissions_user_created;
+CREATE INDEX submissions_user_created ON submissions (tenant_id, user_id, created_at);
+
+ALTER TABLE quotes ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE quotes ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE assessment_sessions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE assessment_sessions ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- Keys are chosen by clients, so two tenants may use the same one.
+ALTER TABLE idempotency_keys ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, key);
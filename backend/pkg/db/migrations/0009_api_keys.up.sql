@@ -0,0 +1,23 @@
+-- API keys broker systems call the API with, server to server. Only the
+-- SHA-256 hash of a key is stored; scopes are comma separated.
+CREATE TABLE api_keys (
+    id          TEXT PRIMARY KEY,
+    tenant_id   TEXT NOT NULL REFERENCES tenants (id),
+    name        TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    scopes      TEXT NOT NULL,
+    daily_quota INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL,
+    expires_at  TIMESTAMPTZ,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX api_keys_tenant_created ON api_keys (tenant_id, created_at);
+
+-- Requests made with each key per UTC day, checked against its quota.
+CREATE TABLE api_key_usage (
+    key_id   TEXT NOT NULL REFERENCES api_keys (id),
+    day      DATE NOT NULL,
+    requests INTEGER NOT NULL,
+    PRIMARY KEY (key_id, day)
+);
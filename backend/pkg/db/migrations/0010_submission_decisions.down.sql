@@ -0,0 +1,3 @@
+ALTER TABLE results DROP COLUMN IF EXISTS decision_reasons;
+ALTER TABLE results DROP COLUMN IF EXISTS decision;
+ALTER TABLE submissions DROP COLUMN decision_reasons;
@@ -0,0 +1,9 @@
+-- The reasons behind each underwriting decision, next to its outcome, so
+-- referrals and declines can be queried without parsing the result.
+ALTER TABLE submissions ADD COLUMN decision_reasons TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE submissions ALTER COLUMN decision_reasons DROP DEFAULT;
+
+-- The legacy results table gets the decision columns /submit wrote to before
+-- submissions were stored, for databases that still take those writes.
+ALTER TABLE results ADD COLUMN IF NOT EXISTS decision TEXT;
+ALTER TABLE results ADD COLUMN IF NOT EXISTS decision_reasons TEXT;
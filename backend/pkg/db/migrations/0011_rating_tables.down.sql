@@ -0,0 +1,2 @@
+ALTER TABLE results DROP COLUMN IF EXISTS submission_id;
+DROP TABLE rating_tables;
@@ -0,0 +1,20 @@
+-- Each version of a tenant's rating table is one row; the highest version is
+-- used. revenue_bands holds a JSON object of band to {"ratePerMillion",
+-- "deductible"}, industry_multipliers one of industry to multiplier, which
+-- must include "other".
+CREATE TABLE rating_tables (
+    tenant_id              TEXT NOT NULL REFERENCES tenants (id),
+    version                INTEGER NOT NULL,
+    currency               TEXT NOT NULL,
+    revenue_bands          TEXT NOT NULL,
+    industry_multipliers   TEXT NOT NULL,
+    worst_score_multiplier DOUBLE PRECISION NOT NULL,
+    best_score_multiplier  DOUBLE PRECISION NOT NULL,
+    minimum_premium        DOUBLE PRECISION NOT NULL,
+    valid_for_days         INTEGER NOT NULL,
+    PRIMARY KEY (tenant_id, version)
+);
+
+-- The submission id /submit wrote with each result before submissions were
+-- stored.
+ALTER TABLE results ADD COLUMN IF NOT EXISTS submission_id TEXT;
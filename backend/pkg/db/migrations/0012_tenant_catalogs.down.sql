@@ -0,0 +1,13 @@
+-- Only the default tenant's catalog fits the single namespace.
+DELETE FROM questions WHERE tenant_id <> 'default';
+DELETE FROM paradigms WHERE tenant_id <> 'default';
+
+ALTER TABLE questions DROP CONSTRAINT questions_tenant_id_paradigm_id_fkey;
+ALTER TABLE questions DROP CONSTRAINT questions_pkey;
+ALTER TABLE questions ADD PRIMARY KEY (id);
+ALTER TABLE paradigms DROP CONSTRAINT paradigms_pkey;
+ALTER TABLE paradigms ADD PRIMARY KEY (id);
+ALTER TABLE questions ADD FOREIGN KEY (paradigm_id) REFERENCES paradigms (id);
+
+ALTER TABLE questions DROP COLUMN tenant_id;
+ALTER TABLE paradigms DROP COLUMN tenant_id;
@@ -0,0 +1,23 @@
+-- Each tenant authors its own paradigms and questions. Existing rows become
+-- the default tenant's catalog, and every other tenant starts from a copy of
+-- it with the same ids, so ids are unique per tenant only. Underwriting
+-- rules stay shared.
+ALTER TABLE paradigms ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE paradigms ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE questions ADD COLUMN tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants (id);
+ALTER TABLE questions ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE questions DROP CONSTRAINT questions_paradigm_id_fkey;
+ALTER TABLE questions DROP CONSTRAINT questions_pkey;
+ALTER TABLE questions ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE paradigms DROP CONSTRAINT paradigms_pkey;
+ALTER TABLE paradigms ADD PRIMARY KEY (tenant_id, id);
+ALTER TABLE questions ADD FOREIGN KEY (tenant_id, paradigm_id) REFERENCES paradigms (tenant_id, id);
+
+INSERT INTO paradigms (tenant_id, id, name, description, position, retired, revision)
+SELECT t.id, p.id, p.name, p.description, p.position, p.retired, 1
+FROM tenants t CROSS JOIN paradigms p WHERE t.id <> 'default' AND p.tenant_id = 'default';
+
+INSERT INTO questions (tenant_id, id, paradigm_id, text, selector, options, min_selections, max_selections, weight, required, conditions, curve, position, retired, revision)
+SELECT t.id, q.id, q.paradigm_id, q.text, q.selector, q.options, q.min_selections, q.max_selections, q.weight, q.required, q.conditions, q.curve, q.position, q.retired, 1
+FROM tenants t CROSS JOIN questions q WHERE t.id <> 'default' AND q.tenant_id = 'default';